@@ -3,42 +3,35 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/noriah/catnip/dsp"
+	"github.com/noriah/catnip/dsp/meter"
+	"github.com/noriah/catnip/dsp/scale"
 	"github.com/noriah/catnip/fft"
 	"github.com/noriah/catnip/graphic"
 	"github.com/noriah/catnip/input"
-	"github.com/noriah/catnip/util"
+	"github.com/noriah/catnip/input/common/resample"
+	"github.com/noriah/catnip/output"
 
 	"github.com/pkg/errors"
 )
 
 const (
-	// ScalingSlowWindow in seconds
-	ScalingSlowWindow = 5
-	// ScalingFastWindow in seconds
-	ScalingFastWindow = ScalingSlowWindow * 0.2
-	// ScalingDumpPercent is how much we erase on rescale
-	ScalingDumpPercent = 0.60
-	// ScalingResetDeviation standard deviations from the mean before reset
-	ScalingResetDeviation = 1.0
-	// PeakThreshold is the threshold to not draw if the peak is less.
-	PeakThreshold = 0.01
+	// MeterRange is the range of the meters in decibels below full scale.
+	MeterRange = 60.0
+	// MaxSizeScale is how many times the sample size the fft can grow to.
+	MaxSizeScale = 8
 )
 
 // Catnip starts to draw the visualizer on the termbox screen.
 func Catnip(cfg *Config) error {
 	// allocate as much as possible as soon as possible
-	var (
-
-		// slowMax/fastMax
-		slowMax = ((int(ScalingSlowWindow * cfg.SampleRate)) / cfg.SampleSize) * 2
-		fastMax = ((int(ScalingFastWindow * cfg.SampleRate)) / cfg.SampleSize) * 2
-
-		total = ((cfg.ChannelCount * cfg.SampleSize) * 2) + (slowMax + fastMax)
-
-		floatData = make([]float64, total)
-	)
+	var frameRate = cfg.SampleRate / float64(cfg.SampleSize)
+	var maxSize = cfg.SampleSize * MaxSizeScale
 
 	var sessConfig = input.SessionConfig{
 		FrameSize:  cfg.ChannelCount,
@@ -47,54 +40,70 @@ func Catnip(cfg *Config) error {
 	}
 
 	vis := visualizer{
-		cfg: cfg,
-		slowWindow: util.MovingWindow{
-			Capacity: slowMax,
-			Data:     floatData[:slowMax],
-		},
-		fastWindow: util.MovingWindow{
-			Capacity: fastMax,
-			Data:     floatData[slowMax : slowMax+fastMax],
-		},
-
-		fftBuf:    make([]complex128, cfg.SampleSize/2+1),
-		inputBufs: make([][]float64, cfg.ChannelCount),
-		barBufs:   make([][]float64, cfg.ChannelCount),
+		cfg:       cfg,
+		maxSize:   maxSize,
+		inputBufs: input.MakeBuffers(sessConfig),
+		history:   dsp.NewHistory(cfg.ChannelCount, maxSize),
+		frame:     dsp.NewFrame(cfg.ChannelCount, cfg.SampleSize, maxSize),
+		scaler:    scale.New(scale.Kind(cfg.Scaler), frameRate, maxSize),
 
-		plans: make([]*fft.Plan, cfg.ChannelCount),
 		spectrum: dsp.Spectrum{
 			SampleRate: cfg.SampleRate,
 			SampleSize: cfg.SampleSize,
-			Bins:       make([]dsp.Bin, cfg.SampleSize),
-			OldValues:  make([][]float64, cfg.ChannelCount),
+			Bins:       make([]dsp.Bin, maxSize),
+		},
+		chroma: dsp.Chroma{
+			SampleRate: cfg.SampleRate,
+			SampleSize: cfg.SampleSize,
+			Tuning:     cfg.Tuning,
 		},
 
 		bars:    0,
 		display: graphic.Display{},
+
+		keys: make(chan rune, 16),
 	}
 
-	var pos = slowMax + fastMax
-	for idx := range vis.barBufs {
+	stages, err := dsp.ParseStages(cfg.Stages)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse pipeline stages")
+	}
 
-		vis.barBufs[idx] = floatData[pos : pos+cfg.SampleSize]
-		pos += cfg.SampleSize
+	if cfg.Peaks && !hasStage(stages, dsp.StagePeak) {
+		stages = append(stages, dsp.StagePeak)
+	}
 
-		vis.inputBufs[idx] = floatData[pos : pos+cfg.SampleSize]
-		pos += cfg.SampleSize
+	fft.SetPlanner(fft.Planner(cfg.Planner))
+	if err := fft.SetThreads(cfg.FFTThreads); err != nil {
+		return errors.Wrap(err, "failed to set fft threads")
+	}
 
-		vis.plans[idx] = &fft.Plan{
-			Input:  vis.inputBufs[idx],
-			Output: vis.fftBuf,
+	// wisdom is only a cache of plans. without it, plans are found again.
+	var wisdom, _ = fft.WisdomPath()
+	if wisdom != "" {
+		if err := fft.LoadWisdom(wisdom); err != nil {
+			log.Printf("ignoring fft wisdom: %v", err)
 		}
+	}
 
-		vis.spectrum.OldValues[idx] = make([]float64, cfg.SampleSize)
-
-		vis.plans[idx].Init()
+	if err := vis.buildPipelines(stages); err != nil {
+		return errors.Wrap(err, "failed to build the pipeline")
+	}
+	defer vis.transform.Close()
+
+	// saved on the way out to keep plans of resized ffts too. the display is
+	// closed by then, so the error can be printed.
+	if wisdom != "" {
+		defer func() {
+			if err := fft.SaveWisdom(wisdom); err != nil {
+				log.Printf("failed to save fft wisdom: %v", err)
+			}
+		}()
 	}
 
 	// INPUT SETUP
 
-	var backend, err = initBackend(cfg)
+	backend, err := initBackend(cfg)
 	if err != nil {
 		return err
 	}
@@ -103,15 +112,82 @@ func Catnip(cfg *Config) error {
 		return err
 	}
 
-	audio, err := backend.Start(sessConfig)
+	var resampling = cfg.DeviceRate > 0 && cfg.DeviceRate != cfg.SampleRate
+
+	var devConfig = sessConfig
+	if resampling {
+		devConfig = resample.DeviceConfig(sessConfig, cfg.DeviceRate)
+	}
+
+	audio, err := backend.Start(devConfig)
 	defer backend.Close()
 
 	if err != nil {
 		return errors.Wrap(err, "failed to start the input backend")
 	}
 
-	vis.spectrum.SetSmoothing(cfg.SmoothFactor)
+	if resampling {
+		audio = resample.NewSession(audio, devConfig, sessConfig)
+	}
+
 	vis.spectrum.SetWinVar(cfg.WinVar)
+	vis.spectrum.Noise.Mode = dsp.NoiseMode(cfg.NoiseMode)
+	vis.spectrum.Noise.Factor = cfg.NoiseFactor
+	vis.spectrum.Noise.Init(cfg.ChannelCount, maxSize, frameRate)
+
+	vis.chroma.Recalculate()
+
+	if manual, ok := vis.scaler.(*scale.Manual); ok {
+		manual.Gain = cfg.Gain
+	}
+
+	if cfg.Meter {
+		vis.meter = meter.New(cfg.ChannelCount, cfg.SampleRate)
+		// one meter per channel, then momentary and short-term loudness.
+		vis.meters = make([]graphic.Meter, cfg.ChannelCount+2)
+		// integrated loudness and max true peak.
+		vis.meterText = make([]string, 2)
+		vis.meterRange = MeterRange
+	}
+
+	if cfg.Stereo {
+		vis.stereoImage = &graphic.Stereo{
+			Widths: make([]float64, maxSize),
+		}
+	}
+
+	gradient, err := graphic.ParseGradient(cfg.GradientStops)
+	if err != nil {
+		return err
+	}
+
+	out, err := newOutput(cfg, gradient)
+	if err != nil {
+		return err
+	}
+
+	if out != nil {
+		defer out.Close()
+		return vis.runOutput(audio, out)
+	}
+
+	themes, err := loadThemes(cfg.ThemeDir)
+	if err != nil {
+		return err
+	}
+
+	var theme = -1
+	if cfg.Theme != "" {
+		if theme = graphic.FindTheme(themes, cfg.Theme); theme < 0 {
+			return errors.Errorf("unknown theme %q", cfg.Theme)
+		}
+	}
+
+	vis.display.SetTrueColor(cfg.TrueColor || graphic.TrueColor())
+
+	if cfg.Pixels != int(graphic.PixelNone) {
+		vis.display.SetRenderer(graphic.NewPixel(graphic.PixelProtocol(cfg.Pixels)))
+	}
 
 	if err = vis.display.Init(); err != nil {
 		return err
@@ -121,7 +197,17 @@ func Catnip(cfg *Config) error {
 	vis.display.SetSizes(cfg.BarSize, cfg.SpaceSize)
 	vis.display.SetBase(cfg.BaseSize)
 	vis.display.SetDrawType(graphic.DrawType(cfg.DrawType))
+	vis.display.SetBraille(cfg.Braille)
+	vis.display.SetFill(cfg.Fill)
+	vis.display.SetGradient(graphic.GradientMode(cfg.Gradient), gradient)
 	vis.display.SetStyles(cfg.Styles)
+	vis.display.SetThemes(themes)
+	if theme >= 0 {
+		vis.display.SetTheme(theme)
+	}
+	vis.display.SetLabels(dsp.PitchNames[:])
+	vis.display.SetStereo(vis.stereoImage)
+	vis.display.SetKeyFunc(vis.onKey)
 
 	// Root Context
 	ctx, cancel := context.WithCancel(context.Background())
@@ -139,6 +225,69 @@ func Catnip(cfg *Config) error {
 	return nil
 }
 
+// newOutput returns the output that replaces the display, nil for none.
+func newOutput(cfg *Config, gradient graphic.Gradient) (output.Output, error) {
+	switch {
+	case cfg.Status != int(output.StatusNone):
+		var status = output.NewStatus(os.Stdout,
+			output.StatusFormat(cfg.Status), cfg.OutputBars, cfg.FrameRate)
+
+		if len(gradient) > 0 {
+			status.Gradient = gradient
+		}
+
+		return status, nil
+
+	case cfg.Stream != int(output.StreamNone):
+		return output.NewStream(os.Stdout,
+			output.StreamFormat(cfg.Stream), cfg.OutputBars, cfg.FrameRate), nil
+
+	case cfg.Web != "":
+		web, err := output.NewWeb(cfg.Web, cfg.OutputBars, cfg.FrameRate, cfg.Waveform)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to start web visualizer")
+		}
+
+		log.Printf("serving the visualizer on http://%s", web.Addr())
+
+		return web, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+// runOutput runs the session with frames written to out instead of the
+// display, until interrupted or out fails.
+func (vis *visualizer) runOutput(audio input.Session, out output.Output) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	vis.out = out
+	vis.cancel = cancel
+
+	// there is no display to quit with a key.
+	var sig = make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var err = audio.Start(ctx, vis.inputBufs, vis)
+
+	if vis.err != nil {
+		return errors.Wrap(vis.err, "failed to write output")
+	}
+
+	return errors.Wrap(err, "failed to start input session")
+}
+
 func initBackend(cfg *Config) (input.Backend, error) {
 	var backend = input.FindBackend(cfg.Backend)
 	if backend == nil {
@@ -174,3 +323,27 @@ func getDevice(backend input.Backend, cfg *Config) (input.Device, error) {
 
 	return nil, errors.Errorf("device %q not found; check list-devices", cfg.Device)
 }
+
+func hasStage(stages []string, name string) bool {
+	for _, stage := range stages {
+		if stage == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadThemes loads the builtin themes and the themes in dir, the default
+// theme directory if dir is empty.
+func loadThemes(dir string) ([]graphic.Theme, error) {
+	if dir == "" {
+		var err error
+		if dir, err = graphic.ThemeDir(); err != nil {
+			// without a config directory there are only builtin themes.
+			return graphic.BuiltinThemes, nil
+		}
+	}
+
+	return graphic.LoadThemes(dir)
+}
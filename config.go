@@ -2,8 +2,14 @@ package main
 
 import (
 	"errors"
+	"strings"
 
+	"github.com/noriah/catnip/dsp"
+	"github.com/noriah/catnip/dsp/filter"
+	"github.com/noriah/catnip/dsp/scale"
+	"github.com/noriah/catnip/fft"
 	"github.com/noriah/catnip/graphic"
+	"github.com/noriah/catnip/output"
 )
 
 // Config is a temporary struct to define parameters
@@ -12,8 +18,10 @@ type Config struct {
 	Backend string
 	// Device is the device name from list-devices
 	Device string
-	// SampleRate is the rate at which samples are read
+	// SampleRate is the rate at which samples are analyzed
 	SampleRate float64
+	// DeviceRate is the rate at which samples are read, if not SampleRate
+	DeviceRate float64
 	//LoCutFrqq is the low end of our audio spectrum
 	LoCutFreq float64
 	// HiCutFreq is the high end of our audio spectrum
@@ -22,6 +30,29 @@ type Config struct {
 	SmoothFactor float64
 	// WinVar factor of distribution
 	WinVar float64
+	// Tuning is the frequency of A4 used for pitch classes
+	Tuning float64
+	// NoiseMode is how the noise floor is removed (0 off, 1 subtract, 2 gate)
+	NoiseMode int
+	// NoiseFactor is the factor the noise floor is multiplied by
+	NoiseFactor float64
+	// Scaler is the scaling strategy (0 window, 1 agc, 2 manual, 3 band)
+	Scaler int
+	// Gain is the gain of the manual scaler in decibels
+	Gain float64
+	// FFT is the fft implementation (0 auto, 1 fftw, 2 gonum, 3 split radix,
+	// 4 split radix in float32)
+	FFT int
+	// Planner is how hard FFTW plans (0 estimate, 1 measure, 2 patient)
+	Planner int
+	// FFTThreads is the number of threads FFTW runs plans on
+	FFTThreads int
+	// Filters are the specs of the filters applied to the input
+	Filters []string
+	// Stages is the comma separated order of the dsp pipeline stages
+	Stages string
+	// Peaks determines if we track and draw bar peaks
+	Peaks bool
 	// BaseSize number of cells wide/high the base is
 	BaseSize int
 	// BarSize is the size of bars, in columns/rows
@@ -36,6 +67,48 @@ type Config struct {
 	Combine bool
 	// DrawType is the draw type
 	DrawType int
+	// Braille determines if bars are drawn with braille dots
+	Braille bool
+	// Fill determines if bars are stretched to fill the whole width
+	Fill bool
+	// Gradient is what the bar gradient runs across (0 off, 1 height,
+	// 2 frequency, 3 channel)
+	Gradient int
+	// GradientStops are the colors of the gradient, as graphic.ParseColor
+	// takes them
+	GradientStops []string
+	// TrueColor forces 24-bit colors, they are used when the terminal says
+	// it supports them anyway
+	TrueColor bool
+	// Pixels is the terminal graphics protocol bars are drawn in pixels with
+	// (0 off, 1 kitty, 2 sixel)
+	Pixels int
+	// Status is the format of the status line written to stdout instead of
+	// the display (0 off, 1 plain, 2 waybar, 3 i3bar, 4 tmux)
+	Status int
+	// Stream is the format of the bar values written to stdout per frame
+	// instead of the display (0 off, 1 json lines, 2 csv, 3 binary)
+	Stream int
+	// Web is the address a browser visualizer is served on instead of the
+	// display, empty for none
+	Web string
+	// Waveform determines if the web visualizer gets the waveform too
+	Waveform bool
+	// OutputBars is the number of bars per channel of the status line, stream
+	// and web outputs
+	OutputBars int
+	// FrameRate is the most frames written to an output a second, 0 for the
+	// default of the output
+	FrameRate float64
+	// Theme is the name of the theme to start with, empty keeps the colors
+	// given by flags
+	Theme string
+	// ThemeDir is the directory theme files are loaded from
+	ThemeDir string
+	// Meter determines if we draw level and loudness meters
+	Meter bool
+	// Stereo determines if we draw the correlation and stereo width
+	Stereo bool
 	// Styles is the configuration for bar color styles
 	Styles graphic.Styles
 }
@@ -54,6 +127,14 @@ func NewZeroConfig() Config {
 		SampleRate:   44100,
 		SmoothFactor: 80.15,
 		WinVar:       0.50, // Deprecated
+		Tuning:       dsp.DefaultTuning,
+		NoiseMode:    int(dsp.NoiseOff),
+		NoiseFactor:  dsp.NoiseFactor,
+		Scaler:       int(scale.KindWindow),
+		Planner:      int(fft.PlannerMeasure),
+		FFTThreads:   1,
+		OutputBars:   16,
+		Stages:       strings.Join(dsp.DefaultStages, ","),
 		BaseSize:     1,
 		BarSize:      2,
 		SpaceSize:    1,
@@ -75,6 +156,14 @@ func (cfg *Config) Sanitize() error {
 		return errors.New("sample size too small (4+ required)")
 	}
 
+	if cfg.DeviceRate < 0.0 {
+		return errors.New("device rate must not be negative")
+	}
+
+	if cfg.DeviceRate > 0.0 && cfg.DeviceRate*float64(cfg.SampleSize) < 4*cfg.SampleRate {
+		return errors.New("device rate too low for sample size")
+	}
+
 	switch {
 
 	case cfg.ChannelCount > 2:
@@ -85,6 +174,89 @@ func (cfg *Config) Sanitize() error {
 
 	}
 
+	if cfg.Stereo && cfg.ChannelCount != 2 {
+		return errors.New("stereo image needs 2 channels")
+	}
+
+	if cfg.NoiseMode < int(dsp.NoiseOff) || cfg.NoiseMode > int(dsp.NoiseGate) {
+		return errors.New("invalid noise mode (0, 1, 2)")
+	}
+
+	if cfg.Scaler < int(scale.KindWindow) || cfg.Scaler >= int(scale.KindMax) {
+		return errors.New("invalid scaler (0, 1, 2, 3)")
+	}
+
+	if cfg.NoiseFactor <= 0.0 {
+		return errors.New("noise factor must be above 0")
+	}
+
+	if cfg.Tuning <= 0.0 {
+		return errors.New("tuning must be above 0 Hz")
+	}
+
+	if cfg.FFT < int(fft.KindAuto) || cfg.FFT >= int(fft.KindMax) {
+		return errors.New("invalid fft (0, 1, 2, 3, 4)")
+	}
+
+	if cfg.FFT == int(fft.KindFFTW) && !fft.FFTW {
+		return errors.New("built without fftw")
+	}
+
+	if cfg.Planner < int(fft.PlannerEstimate) || cfg.Planner >= int(fft.PlannerMax) {
+		return errors.New("invalid planner (0, 1, 2)")
+	}
+
+	if cfg.FFTThreads < 1 {
+		return errors.New("too few fft threads (1 min)")
+	}
+
+	if _, err := filter.ParseChain(cfg.Filters, cfg.SampleRate); err != nil {
+		return err
+	}
+
+	if cfg.Gradient < int(graphic.GradientNone) || cfg.Gradient >= int(graphic.GradientMax) {
+		return errors.New("invalid gradient (0, 1, 2, 3)")
+	}
+
+	if cfg.Status < int(output.StatusNone) || cfg.Status >= int(output.StatusMax) {
+		return errors.New("invalid status (0, 1, 2, 3, 4)")
+	}
+
+	if cfg.Stream < int(output.StreamNone) || cfg.Stream >= int(output.StreamMax) {
+		return errors.New("invalid stream (0, 1, 2, 3)")
+	}
+
+	var outputs int
+	for _, set := range []bool{
+		cfg.Status != int(output.StatusNone),
+		cfg.Stream != int(output.StreamNone),
+		cfg.Web != "",
+	} {
+		if set {
+			outputs++
+		}
+	}
+
+	if outputs > 1 {
+		return errors.New("only one of status, stream and web can be used")
+	}
+
+	if cfg.OutputBars < 1 {
+		return errors.New("too few output bars (1 min)")
+	}
+
+	if cfg.FrameRate < 0.0 {
+		return errors.New("frame rate must not be negative")
+	}
+
+	if cfg.Pixels < int(graphic.PixelNone) || cfg.Pixels >= int(graphic.PixelMax) {
+		return errors.New("invalid pixels (0, 1, 2)")
+	}
+
+	if _, err := graphic.ParseGradient(cfg.GradientStops); err != nil {
+		return err
+	}
+
 	switch {
 	case cfg.WinVar > 1.0:
 		cfg.WinVar = 1.0
@@ -0,0 +1,114 @@
+package dsp
+
+import (
+	"math"
+)
+
+// PitchClasses is the number of pitch classes in an octave.
+const PitchClasses = 12
+
+// DefaultTuning is the default frequency of A4 in Hz.
+const DefaultTuning = 440.0
+
+// PitchNames are the names of the pitch classes, starting at C.
+var PitchNames = [PitchClasses]string{
+	"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B",
+}
+
+// Chroma folds the energy of a spectrum into pitch classes
+type Chroma struct {
+	SampleSize int       // number of samples per slice
+	SampleRate float64   // audio sample rate
+	Tuning     float64   // frequency of A4 in Hz
+	classes    []int     // pitch class for each fft index, -1 to skip
+	counts     []int     // number of fft indexes per pitch class
+	energy     []float64 // energy accumulator per pitch class
+}
+
+// Recalculate maps every fft index in our range to a pitch class.
+//
+// The range starts where fft bins get narrower than a semitone, about 700Hz
+// at 44100Hz and 1024 samples, and never below the bass dividing frequency.
+// Below that the bins are too wide to tell notes apart. It ends at the
+// midrange dividing frequency, as above that we mostly get harmonics.
+func (c *Chroma) Recalculate() {
+	var fftSize = c.SampleSize/2 + 1
+
+	if len(c.classes) != fftSize {
+		c.classes = make([]int, fftSize)
+	}
+
+	if c.energy == nil {
+		c.energy = make([]float64, PitchClasses)
+		c.counts = make([]int, PitchClasses)
+	}
+
+	for idx := range c.counts {
+		c.counts[idx] = 0
+	}
+
+	if c.Tuning <= 0.0 {
+		c.Tuning = DefaultTuning
+	}
+
+	var binWidth = c.SampleRate / float64(c.SampleSize)
+
+	// a semitone above f is f * (2^(1/12) - 1) wide.
+	var lo = math.Max(Frequencies[1], binWidth/(math.Exp2(1.0/12.0)-1.0))
+	var hi = math.Min(c.SampleRate/2, Frequencies[3])
+
+	for idx := range c.classes {
+		freq := float64(idx) * binWidth
+		if freq < lo || freq > hi {
+			c.classes[idx] = -1
+			continue
+		}
+
+		c.classes[idx] = pitchClass(freq, c.Tuning)
+		c.counts[c.classes[idx]]++
+	}
+}
+
+// pitchClass returns the pitch class of freq, with 0 being C.
+func pitchClass(freq, tuning float64) int {
+	// semitones away from A4, A being 9 semitones above C.
+	var note = int(math.Round(12.0*math.Log2(freq/tuning))) + 9
+
+	note %= PitchClasses
+	if note < 0 {
+		note += PitchClasses
+	}
+
+	return note
+}
+
+// Bin folds the spectrum of every channel into pitch classes and fills the
+// bars with the magnitude of each class. The energy of a class is averaged
+// over its fft indexes, as higher octaves span more of them.
+func (c *Chroma) Bin(f *Frame) {
+	f.Count = PitchClasses
+
+	for ch, buf := range f.Bars[:f.Channels] {
+		src := f.Spectrum[ch]
+
+		for idx := range c.energy {
+			c.energy[idx] = 0
+		}
+
+		for idx, class := range c.classes {
+			if class < 0 || idx >= len(src) {
+				continue
+			}
+
+			re, im := real(src[idx]), imag(src[idx])
+			c.energy[class] += (re * re) + (im * im)
+		}
+
+		for idx, e := range c.energy {
+			buf[idx] = 0
+			if c.counts[idx] > 0 {
+				buf[idx] = math.Sqrt(e / float64(c.counts[idx]))
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChromaRange(t *testing.T) {
+	var c = Chroma{SampleSize: 1024, SampleRate: 44100}
+	c.Recalculate()
+
+	var binWidth = c.SampleRate / float64(c.SampleSize)
+
+	for idx, class := range c.classes {
+		freq := float64(idx) * binWidth
+
+		// neighbouring semitones fall in the same bin below about 700Hz.
+		if class >= 0 && freq < 700 {
+			t.Errorf("%.0fHz is used, but bins are wider than a semitone", freq)
+		}
+
+		if class < 0 && freq > 750 && freq < Frequencies[3] {
+			t.Errorf("%.0fHz is not used", freq)
+		}
+	}
+}
+
+func TestChromaFlat(t *testing.T) {
+	var c = Chroma{SampleSize: 8192, SampleRate: 44100}
+	c.Recalculate()
+
+	var f = NewFrame(1, 8192, 8192)
+	for idx := range f.Spectrum[0] {
+		f.Spectrum[0][idx] = complex(1, 0)
+	}
+
+	c.Bin(f)
+
+	// a flat spectrum has the same energy in every class, however many
+	// bins each of them spans.
+	for idx, v := range f.Bars[0][:PitchClasses] {
+		if math.Abs(v-1) > 1e-9 {
+			t.Errorf("%s: %v, want 1", PitchNames[idx], v)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Package filter provides time-domain filters for audio samples
+//
+// See https://www.w3.org/TR/audio-eq-cookbook/
+package filter
+
+// Biquad is a second order IIR filter in transposed direct form II.
+//
+// Coefficients are normalized so that a0 is 1.
+type Biquad struct {
+	B0, B1, B2 float64
+	A1, A2     float64
+
+	z1, z2 float64 // state
+}
+
+// Tick filters a single sample.
+func (f *Biquad) Tick(x float64) float64 {
+	y := (f.B0 * x) + f.z1
+	f.z1 = (f.B1 * x) - (f.A1 * y) + f.z2
+	f.z2 = (f.B2 * x) - (f.A2 * y)
+	return y
+}
+
+// Process filters buf in place.
+func (f *Biquad) Process(buf []float64) {
+	for n, x := range buf {
+		buf[n] = f.Tick(x)
+	}
+}
+
+// Reset clears the filter state.
+func (f *Biquad) Reset() {
+	f.z1, f.z2 = 0, 0
+}
@@ -0,0 +1,123 @@
+package filter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Chain is a series of filters.
+type Chain []Biquad
+
+// Process filters buf in place through every filter of the chain.
+func (c Chain) Process(buf []float64) {
+	for idx := range c {
+		c[idx].Process(buf)
+	}
+}
+
+// Reset clears the state of every filter of the chain.
+func (c Chain) Reset() {
+	for idx := range c {
+		c[idx].Reset()
+	}
+}
+
+// Parse returns the filter described by spec at rate.
+//
+// A spec is a kind and up to two parameters, separated by colons:
+//
+//	hp:freq[:q]   high-pass
+//	lp:freq[:q]   low-pass
+//	bp:freq[:q]   band-pass
+//	ls:freq:gain  low shelf, gain in dB
+//	hs:freq:gain  high shelf, gain in dB
+//	pe[:coef]     pre-emphasis, coef in [0, 1)
+func Parse(spec string, rate float64) (Biquad, error) {
+	var parts = strings.Split(strings.TrimSpace(spec), ":")
+
+	var params = make([]float64, len(parts)-1)
+	for idx, part := range parts[1:] {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return Biquad{}, errors.Errorf("filter %q: bad parameter %q", spec, part)
+		}
+		params[idx] = v
+	}
+
+	var param = func(idx int, def float64) float64 {
+		if idx < len(params) {
+			return params[idx]
+		}
+		return def
+	}
+
+	var kind = strings.ToLower(parts[0])
+
+	if kind == "pe" {
+		if len(params) > 1 {
+			return Biquad{}, errors.Errorf("filter %q: too many parameters", spec)
+		}
+
+		coef := param(0, PreEmphasis)
+		if !(coef >= 0.0 && coef < 1.0) {
+			return Biquad{}, errors.Errorf("filter %q: coefficient out of range [0, 1)", spec)
+		}
+
+		return NewPreEmphasis(coef), nil
+	}
+
+	if len(params) < 1 || len(params) > 2 {
+		return Biquad{}, errors.Errorf("filter %q: expected a frequency and up to one parameter", spec)
+	}
+
+	var freq = params[0]
+	if freq <= 0.0 || freq >= rate/2.0 {
+		return Biquad{}, errors.Errorf("filter %q: frequency out of range (0, %g)", spec, rate/2.0)
+	}
+
+	switch kind {
+	case "hp", "lp", "bp":
+		q := param(1, ButterworthQ)
+		if q <= 0.0 {
+			return Biquad{}, errors.Errorf("filter %q: q must be above 0", spec)
+		}
+
+		switch kind {
+		case "hp":
+			return NewHighPass(freq, q, rate), nil
+		case "lp":
+			return NewLowPass(freq, q, rate), nil
+		default:
+			return NewBandPass(freq, q, rate), nil
+		}
+
+	case "ls", "hs":
+		if len(params) != 2 {
+			return Biquad{}, errors.Errorf("filter %q: shelves need a gain", spec)
+		}
+
+		if kind == "ls" {
+			return NewLowShelf(freq, params[1], rate), nil
+		}
+		return NewHighShelf(freq, params[1], rate), nil
+	}
+
+	return Biquad{}, errors.Errorf("filter %q: unknown kind %q", spec, parts[0])
+}
+
+// ParseChain returns a chain of the filters described by specs at rate.
+func ParseChain(specs []string, rate float64) (Chain, error) {
+	var c = make(Chain, len(specs))
+
+	for idx, spec := range specs {
+		f, err := Parse(spec, rate)
+		if err != nil {
+			return nil, err
+		}
+		c[idx] = f
+	}
+
+	return c, nil
+}
@@ -0,0 +1,102 @@
+package filter
+
+import (
+	"math"
+)
+
+const (
+	// ButterworthQ is the q of a maximally flat second order filter.
+	ButterworthQ = math.Sqrt2 / 2.0
+	// PreEmphasis is the default pre-emphasis coefficient.
+	PreEmphasis = 0.97
+)
+
+// normalize returns a biquad from coefficients that are not normalized.
+func normalize(b0, b1, b2, a0, a1, a2 float64) Biquad {
+	return Biquad{
+		B0: b0 / a0,
+		B1: b1 / a0,
+		B2: b2 / a0,
+		A1: a1 / a0,
+		A2: a2 / a0,
+	}
+}
+
+// omega returns the cosine of the normalized angular frequency of freq and
+// its sine over 2q.
+func omega(freq, q, rate float64) (float64, float64) {
+	var w0 = 2.0 * math.Pi * freq / rate
+	return math.Cos(w0), math.Sin(w0) / (2.0 * q)
+}
+
+// NewHighPass returns a high-pass filter at freq.
+func NewHighPass(freq, q, rate float64) Biquad {
+	var cos, alpha = omega(freq, q, rate)
+
+	return normalize(
+		(1.0+cos)/2.0, -(1.0 + cos), (1.0+cos)/2.0,
+		1.0+alpha, -2.0*cos, 1.0-alpha,
+	)
+}
+
+// NewLowPass returns a low-pass filter at freq.
+func NewLowPass(freq, q, rate float64) Biquad {
+	var cos, alpha = omega(freq, q, rate)
+
+	return normalize(
+		(1.0-cos)/2.0, 1.0-cos, (1.0-cos)/2.0,
+		1.0+alpha, -2.0*cos, 1.0-alpha,
+	)
+}
+
+// NewBandPass returns a band-pass filter around freq with 0 dB peak gain.
+func NewBandPass(freq, q, rate float64) Biquad {
+	var cos, alpha = omega(freq, q, rate)
+
+	return normalize(
+		alpha, 0.0, -alpha,
+		1.0+alpha, -2.0*cos, 1.0-alpha,
+	)
+}
+
+// NewLowShelf returns a shelf that adds gain decibels below freq.
+func NewLowShelf(freq, gain, rate float64) Biquad {
+	var (
+		a          = math.Pow(10.0, gain/40.0)
+		cos, alpha = omega(freq, ButterworthQ, rate)
+		beta       = 2.0 * math.Sqrt(a) * alpha
+	)
+
+	return normalize(
+		a*((a+1.0)-((a-1.0)*cos)+beta),
+		2.0*a*((a-1.0)-((a+1.0)*cos)),
+		a*((a+1.0)-((a-1.0)*cos)-beta),
+		(a+1.0)+((a-1.0)*cos)+beta,
+		-2.0*((a-1.0)+((a+1.0)*cos)),
+		(a+1.0)+((a-1.0)*cos)-beta,
+	)
+}
+
+// NewHighShelf returns a shelf that adds gain decibels above freq.
+func NewHighShelf(freq, gain, rate float64) Biquad {
+	var (
+		a          = math.Pow(10.0, gain/40.0)
+		cos, alpha = omega(freq, ButterworthQ, rate)
+		beta       = 2.0 * math.Sqrt(a) * alpha
+	)
+
+	return normalize(
+		a*((a+1.0)+((a-1.0)*cos)+beta),
+		-2.0*a*((a-1.0)+((a+1.0)*cos)),
+		a*((a+1.0)+((a-1.0)*cos)-beta),
+		(a+1.0)-((a-1.0)*cos)+beta,
+		2.0*((a-1.0)-((a+1.0)*cos)),
+		(a+1.0)-((a-1.0)*cos)-beta,
+	)
+}
+
+// NewPreEmphasis returns a first order pre-emphasis filter,
+// y[n] = x[n] - coef * x[n-1], which boosts the highs.
+func NewPreEmphasis(coef float64) Biquad {
+	return Biquad{B0: 1.0, B1: -coef}
+}
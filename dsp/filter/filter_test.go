@@ -0,0 +1,82 @@
+package filter
+
+import (
+	"math"
+	"testing"
+)
+
+const rate = 44100.0
+
+// gain returns the gain of f on a sine of freq Hz once it settled.
+func gain(f Biquad, freq float64) float64 {
+	var buf = make([]float64, int(rate))
+	for idx := range buf {
+		buf[idx] = math.Sin(2.0 * math.Pi * freq * float64(idx) / rate)
+	}
+
+	f.Process(buf)
+
+	var peak float64
+	for _, v := range buf[len(buf)/2:] {
+		peak = math.Max(peak, math.Abs(v))
+	}
+
+	return peak
+}
+
+func TestHighPassRemovesDC(t *testing.T) {
+	var f = NewHighPass(20, ButterworthQ, rate)
+
+	var buf = make([]float64, int(rate))
+	for idx := range buf {
+		buf[idx] = 0.5
+	}
+
+	f.Process(buf)
+
+	if v := buf[len(buf)-1]; math.Abs(v) > 1e-6 {
+		t.Errorf("dc left at %v", v)
+	}
+}
+
+func TestResponse(t *testing.T) {
+	var tests = []struct {
+		spec       string
+		freq, want float64
+	}{
+		{"hp:100", 1000, 1},
+		{"hp:100", 100, math.Sqrt2 / 2},
+		{"hp:1000", 50, 0},
+		{"lp:1000", 100, 1},
+		{"lp:1000", 1000, math.Sqrt2 / 2},
+		{"lp:1000", 15000, 0},
+		{"bp:1000:2", 1000, 1},
+		{"bp:1000:2", 10000, 0.05},
+		{"ls:200:6", 30, 2},
+		{"ls:200:6", 5000, 1},
+		{"hs:2000:-6", 15000, 0.5},
+		{"hs:2000:-6", 100, 1},
+	}
+
+	for _, test := range tests {
+		f, err := Parse(test.spec, rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if g := gain(f, test.freq); math.Abs(g-test.want) > 0.05 {
+			t.Errorf("%s at %v Hz: gain %.3f, want %.3f", test.spec, test.freq, g, test.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"", "hp", "hp:x", "hp:0", "lp:30000", "hp:100:0", "ls:100", "xx:100", "pe:1:2",
+		"pe:1", "pe:-0.5", "pe:1.5", "pe:nan",
+	} {
+		if _, err := Parse(spec, rate); err == nil {
+			t.Errorf("%q did not fail", spec)
+		}
+	}
+}
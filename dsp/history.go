@@ -0,0 +1,54 @@
+package dsp
+
+// History keeps the latest samples of every channel, so frames can look
+// further back than a single input buffer.
+type History struct {
+	bufs [][]float64
+}
+
+// NewHistory allocates a history of size samples for channels channels.
+func NewHistory(channels, size int) *History {
+	var h = &History{
+		bufs: make([][]float64, channels),
+	}
+
+	for idx := range h.bufs {
+		h.bufs[idx] = make([]float64, size)
+	}
+
+	return h
+}
+
+// Write adds the buffers of src to the history and returns the number of new
+// samples. Only buffers the input has written since the last call may be
+// added, as every call moves the history along.
+func (h *History) Write(src [][]float64) int {
+	if len(src) == 0 {
+		return 0
+	}
+
+	for idx, buf := range src {
+		shift(h.bufs[idx], buf)
+	}
+
+	return len(src[0])
+}
+
+// Read copies the latest len(dst[ch]) samples of channel ch into dst.
+func (h *History) Read(dst [][]float64) {
+	for idx, buf := range dst {
+		src := h.bufs[idx]
+		copy(buf, src[len(src)-len(buf):])
+	}
+}
+
+// shift moves the samples of buf back by len(samples) and appends samples.
+func shift(buf, samples []float64) {
+	if len(samples) >= len(buf) {
+		copy(buf, samples[len(samples)-len(buf):])
+		return
+	}
+
+	copy(buf, buf[len(samples):])
+	copy(buf[len(buf)-len(samples):], samples)
+}
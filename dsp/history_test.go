@@ -0,0 +1,63 @@
+package dsp
+
+import (
+	"testing"
+
+	"github.com/noriah/catnip/dsp/filter"
+)
+
+func TestHistory(t *testing.T) {
+	var h = NewHistory(1, 6)
+	var dst = [][]float64{make([]float64, 4)}
+
+	if n := h.Write([][]float64{{1, 2}}); n != 2 {
+		t.Errorf("wrote %d new samples, want 2", n)
+	}
+
+	// silence repeats the same values, yet they are new samples.
+	if n := h.Write([][]float64{{0, 0}}); n != 2 {
+		t.Errorf("wrote %d new samples, want 2", n)
+	}
+
+	if n := h.Write([][]float64{{0, 0}}); n != 2 {
+		t.Errorf("repeated values wrote %d new samples, want 2", n)
+	}
+
+	h.Write([][]float64{{3, 4}})
+	h.Write([][]float64{{5, 6}})
+	h.Write([][]float64{{7, 8}})
+	h.Read(dst)
+
+	for idx, want := range []float64{5, 6, 7, 8} {
+		if dst[0][idx] != want {
+			t.Fatalf("read %v, want [5 6 7 8]", dst[0])
+		}
+	}
+}
+
+func TestFilterFresh(t *testing.T) {
+	var h = NewHistory(1, 8)
+	var f = NewFrame(1, 4, 8)
+	var fl = NewFilter(filter.Chain{filter.NewPreEmphasis(1)}, 1, 8)
+
+	// every sample is the square of its index, so the first difference of
+	// sample n is 2n - 1.
+	for block := 0; block < 6; block++ {
+		n := float64(block * 2)
+		f.Fresh = h.Write([][]float64{{n * n, (n + 1) * (n + 1)}})
+		h.Read(f.Input)
+
+		fl.Process(f)
+
+		if block < 2 {
+			continue
+		}
+
+		for idx, v := range f.Input[0] {
+			x := n - 2 + float64(idx)
+			if want := (2 * x) - 1; v != want {
+				t.Fatalf("block %d: filtered %v, want sample %d to be %v", block, f.Input[0], idx, want)
+			}
+		}
+	}
+}
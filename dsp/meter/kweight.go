@@ -0,0 +1,60 @@
+package meter
+
+import (
+	"math"
+
+	"github.com/noriah/catnip/dsp/filter"
+)
+
+// kWeighting is the two stage pre-filter from BS.1770.
+//
+// The coefficients are recalculated for the sample rate, following the
+// analog prototypes that libebur128 derived from the 48kHz tables.
+type kWeighting struct {
+	shelf    filter.Biquad // head related high shelf
+	highPass filter.Biquad // revised low-frequency B-curve
+}
+
+func (k *kWeighting) init(rate float64) {
+	var (
+		f0 = 1681.974450955533
+		g  = 3.999843853973347
+		q  = 0.7071752369554196
+
+		kk = math.Tan(math.Pi * f0 / rate)
+		vh = math.Pow(10.0, g/20.0)
+		vb = math.Pow(vh, 0.4996667741545416)
+		a0 = 1.0 + (kk / q) + (kk * kk)
+	)
+
+	k.shelf = filter.Biquad{
+		B0: (vh + (vb * kk / q) + (kk * kk)) / a0,
+		B1: 2.0 * ((kk * kk) - vh) / a0,
+		B2: (vh - (vb * kk / q) + (kk * kk)) / a0,
+		A1: 2.0 * ((kk * kk) - 1.0) / a0,
+		A2: (1.0 - (kk / q) + (kk * kk)) / a0,
+	}
+
+	f0 = 38.13547087602444
+	q = 0.5003270373238773
+
+	kk = math.Tan(math.Pi * f0 / rate)
+	a0 = 1.0 + (kk / q) + (kk * kk)
+
+	k.highPass = filter.Biquad{
+		B0: 1.0,
+		B1: -2.0,
+		B2: 1.0,
+		A1: 2.0 * ((kk * kk) - 1.0) / a0,
+		A2: (1.0 - (kk / q) + (kk * kk)) / a0,
+	}
+}
+
+func (k *kWeighting) tick(x float64) float64 {
+	return k.highPass.Tick(k.shelf.Tick(x))
+}
+
+func (k *kWeighting) reset() {
+	k.shelf.Reset()
+	k.highPass.Reset()
+}
@@ -0,0 +1,151 @@
+package meter
+
+import (
+	"math"
+)
+
+const (
+	// momentarySteps is the number of 100ms steps in the momentary window.
+	momentarySteps = 4
+	// shortTermSteps is the number of 100ms steps in the short-term window.
+	shortTermSteps = 30
+
+	// AbsoluteGate is the absolute gating threshold in LUFS.
+	AbsoluteGate = -70.0
+	// RelativeGate is the relative gating threshold in LU.
+	RelativeGate = -10.0
+
+	// histogram of gating blocks from AbsoluteGate to histMax LUFS.
+	histMax  = 10.0
+	histStep = 0.1
+	histBins = int((histMax - AbsoluteGate) / histStep)
+)
+
+// loudness keeps the K-weighted energy of the last 3 seconds in 100ms steps,
+// and a histogram of every gating block for integrated loudness.
+//
+// Gating blocks are 400ms long and overlap by 75%, so every step completes
+// a new block.
+type loudness struct {
+	stepSize  int     // samples per step
+	stepFill  int     // samples in the current step
+	stepSum   float64 // energy sum of the current step
+	stepCount int     // number of completed steps
+
+	steps [shortTermSteps]float64 // mean energy of the last steps
+	index int                     // index of the next step
+
+	histCount  [histBins]float64
+	histEnergy [histBins]float64
+}
+
+func (l *loudness) init(rate float64) {
+	l.stepSize = int(math.Max(math.Round(rate/10.0), 1))
+}
+
+func (l *loudness) reset() {
+	var size = l.stepSize
+	*l = loudness{stepSize: size}
+}
+
+// add adds the energy of one frame, summed over channels.
+func (l *loudness) add(energy float64) {
+	l.stepSum += energy
+
+	if l.stepFill++; l.stepFill < l.stepSize {
+		return
+	}
+
+	l.steps[l.index] = l.stepSum / float64(l.stepSize)
+	if l.index++; l.index >= shortTermSteps {
+		l.index = 0
+	}
+
+	l.stepSum = 0
+	l.stepFill = 0
+	l.stepCount++
+
+	if l.stepCount < momentarySteps {
+		return
+	}
+
+	var block = l.mean(momentarySteps)
+	if lufs := toLUFS(block); lufs > AbsoluteGate {
+		bin := int((lufs - AbsoluteGate) / histStep)
+		if bin >= histBins {
+			bin = histBins - 1
+		}
+
+		l.histCount[bin]++
+		l.histEnergy[bin] += block
+	}
+}
+
+// mean returns the mean energy of the last count steps.
+func (l *loudness) mean(count int) float64 {
+	var sum float64
+
+	idx := l.index
+	for n := 0; n < count; n++ {
+		if idx--; idx < 0 {
+			idx = shortTermSteps - 1
+		}
+		sum += l.steps[idx]
+	}
+
+	return sum / float64(count)
+}
+
+// integrated returns the gated loudness of all blocks in the histogram.
+func (l *loudness) integrated() float64 {
+	var count, energy float64
+	for bin := range l.histCount {
+		count += l.histCount[bin]
+		energy += l.histEnergy[bin]
+	}
+
+	if count == 0 {
+		return math.Inf(-1)
+	}
+
+	var gate = toLUFS(energy/count) + RelativeGate
+
+	count, energy = 0, 0
+	for bin := range l.histCount {
+		if AbsoluteGate+(float64(bin)*histStep) < gate {
+			continue
+		}
+
+		count += l.histCount[bin]
+		energy += l.histEnergy[bin]
+	}
+
+	if count == 0 {
+		return math.Inf(-1)
+	}
+
+	return toLUFS(energy / count)
+}
+
+func (l *loudness) values() Loudness {
+	var v = Loudness{
+		Momentary:  math.Inf(-1),
+		ShortTerm:  math.Inf(-1),
+		Integrated: l.integrated(),
+	}
+
+	if l.stepCount >= momentarySteps {
+		v.Momentary = toLUFS(l.mean(momentarySteps))
+	}
+
+	if l.stepCount >= shortTermSteps {
+		v.ShortTerm = toLUFS(l.mean(shortTermSteps))
+	}
+
+	return v
+}
+
+// toLUFS converts a mean K-weighted energy to LUFS.
+func toLUFS(energy float64) float64 {
+	return -0.691 + (10.0 * math.Log10(energy))
+}
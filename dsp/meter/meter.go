@@ -0,0 +1,124 @@
+// Package meter provides level and loudness metering
+//
+// Loudness follows ITU-R BS.1770 and EBU R 128:
+//
+// https://www.itu.int/rec/R-REC-BS.1770
+// https://tech.ebu.ch/docs/r/r128.pdf
+// https://tech.ebu.ch/docs/tech/tech3341.pdf
+package meter
+
+import (
+	"math"
+)
+
+// Levels are the levels of a single channel over the last written block.
+// All values are linear, with 1.0 being full scale.
+type Levels struct {
+	RMS      float64 // root mean square
+	Peak     float64 // sample peak
+	TruePeak float64 // 4x oversampled peak
+}
+
+// Loudness holds loudness values in LUFS. A value is -Inf until enough audio
+// has been written to measure it.
+type Loudness struct {
+	Momentary  float64 // 400ms window
+	ShortTerm  float64 // 3s window
+	Integrated float64 // gated, since the last reset
+}
+
+// Meter measures the levels and loudness of a stream of blocks.
+type Meter struct {
+	Levels      []Levels // levels for each channel
+	Loudness    Loudness // loudness of all channels
+	MaxTruePeak float64  // highest true peak since the last reset
+
+	channels []channel
+	loudness loudness
+}
+
+type channel struct {
+	kWeight  kWeighting
+	truePeak truePeak
+}
+
+// New returns a meter for count channels at sampleRate.
+func New(count int, sampleRate float64) *Meter {
+	var m = &Meter{
+		Levels:   make([]Levels, count),
+		channels: make([]channel, count),
+	}
+
+	for idx := range m.channels {
+		m.channels[idx].kWeight.init(sampleRate)
+	}
+
+	m.loudness.init(sampleRate)
+	m.Reset()
+
+	return m
+}
+
+// Reset clears all measurements.
+func (m *Meter) Reset() {
+	for idx := range m.channels {
+		m.channels[idx].kWeight.reset()
+		m.channels[idx].truePeak.reset()
+		m.Levels[idx] = Levels{}
+	}
+
+	m.MaxTruePeak = 0
+	m.loudness.reset()
+	m.Loudness = m.loudness.values()
+}
+
+// Write measures one block of samples for each channel. All channels must
+// have the same number of samples.
+func (m *Meter) Write(bufs [][]float64) {
+	if len(bufs) == 0 {
+		return
+	}
+
+	var size = len(bufs[0])
+	if size == 0 {
+		return
+	}
+
+	for idx := range m.channels {
+		m.Levels[idx] = Levels{}
+	}
+
+	for n := 0; n < size; n++ {
+		var energy float64
+
+		for idx, buf := range bufs[:len(m.channels)] {
+			ch := &m.channels[idx]
+			lv := &m.Levels[idx]
+
+			x := buf[n]
+
+			lv.RMS += x * x
+			lv.Peak = math.Max(lv.Peak, math.Abs(x))
+			lv.TruePeak = math.Max(lv.TruePeak, ch.truePeak.tick(x))
+
+			// channel weights are all 1.0 for mono and stereo.
+			k := ch.kWeight.tick(x)
+			energy += k * k
+		}
+
+		m.loudness.add(energy)
+	}
+
+	for idx := range m.Levels {
+		lv := &m.Levels[idx]
+		lv.RMS = math.Sqrt(lv.RMS / float64(size))
+		m.MaxTruePeak = math.Max(m.MaxTruePeak, lv.TruePeak)
+	}
+
+	m.Loudness = m.loudness.values()
+}
+
+// DB converts a linear level to decibels relative to full scale.
+func DB(level float64) float64 {
+	return 20.0 * math.Log10(level)
+}
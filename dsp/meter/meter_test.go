@@ -0,0 +1,211 @@
+package meter
+
+import (
+	"math"
+	"testing"
+)
+
+const testRate = 48000.0
+
+// writeSine writes seconds of a sine at freq with a peak of dbfs to every
+// channel of m in 100ms blocks.
+func writeSine(m *Meter, freq, dbfs, seconds float64) {
+	var amp = math.Pow(10.0, dbfs/20.0)
+	var block = int(testRate / 10.0)
+
+	var bufs = make([][]float64, len(m.Levels))
+	for idx := range bufs {
+		bufs[idx] = make([]float64, block)
+	}
+
+	for n := 0; n < int(seconds*10.0); n++ {
+		for idx := range bufs[0] {
+			v := amp * math.Sin(2.0*math.Pi*freq*float64((n*block)+idx)/testRate)
+			for _, buf := range bufs {
+				buf[idx] = v
+			}
+		}
+
+		m.Write(bufs)
+	}
+}
+
+func near(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestLevels(t *testing.T) {
+	var m = New(1, testRate)
+
+	// a whole number of cycles, with samples on the crests.
+	writeSine(m, 1000, 0, 0.1)
+
+	var lv = m.Levels[0]
+
+	if !near(lv.RMS, math.Sqrt2/2.0, 1e-9) {
+		t.Errorf("rms %v, want %v", lv.RMS, math.Sqrt2/2.0)
+	}
+
+	if !near(DB(lv.RMS), -3.01, 0.01) {
+		t.Errorf("rms %v dBFS, want -3.01", DB(lv.RMS))
+	}
+
+	if !near(lv.Peak, 1.0, 1e-9) {
+		t.Errorf("peak %v, want 1", lv.Peak)
+	}
+}
+
+func TestLoudnessSine(t *testing.T) {
+	// EBU Tech 3341: a 997Hz sine at -20 dBFS reads -23 LUFS on one channel,
+	// as does one at -23 dBFS on both channels of a stereo pair.
+	var tests = []struct {
+		channels int
+		dbfs     float64
+	}{
+		{1, -20},
+		{2, -23},
+	}
+
+	for _, tt := range tests {
+		var m = New(tt.channels, testRate)
+
+		writeSine(m, 997, tt.dbfs, 10)
+
+		var l = m.Loudness
+
+		if !near(l.Momentary, -23, 0.1) {
+			t.Errorf("%d channels: momentary %v LUFS, want -23", tt.channels, l.Momentary)
+		}
+
+		if !near(l.ShortTerm, -23, 0.1) {
+			t.Errorf("%d channels: short-term %v LUFS, want -23", tt.channels, l.ShortTerm)
+		}
+
+		if !near(l.Integrated, -23, 0.1) {
+			t.Errorf("%d channels: integrated %v LUFS, want -23", tt.channels, l.Integrated)
+		}
+	}
+}
+
+func TestLoudnessWarmUp(t *testing.T) {
+	var m = New(1, testRate)
+
+	writeSine(m, 997, -20, 0.3)
+
+	if l := m.Loudness; !math.IsInf(l.Momentary, -1) || !math.IsInf(l.ShortTerm, -1) {
+		t.Errorf("got %+v before a full window, want -Inf", l)
+	}
+
+	writeSine(m, 997, -20, 0.1)
+
+	if l := m.Loudness; math.IsInf(l.Momentary, -1) || !math.IsInf(l.ShortTerm, -1) {
+		t.Errorf("got %+v after 400ms, want only momentary", l)
+	}
+}
+
+func TestLoudnessGates(t *testing.T) {
+	var m = New(1, testRate)
+
+	writeSine(m, 997, -20, 10)
+
+	// silence is below the absolute gate.
+	writeSine(m, 997, -100, 10)
+
+	if l := m.Loudness; !near(l.Integrated, -23, 0.2) {
+		t.Errorf("integrated %v LUFS with silence, want -23", l.Integrated)
+	}
+
+	if l := m.Loudness; l.Momentary > AbsoluteGate {
+		t.Errorf("momentary %v LUFS in silence, want below %v", l.Momentary, AbsoluteGate)
+	}
+
+	// -48 LUFS is above the absolute gate, but more than 10 LU below the
+	// rest. counting it would take the integrated loudness down 3 LU.
+	writeSine(m, 997, -45, 10)
+
+	if l := m.Loudness; !near(l.Integrated, -23, 0.2) {
+		t.Errorf("integrated %v LUFS with a quiet part, want -23", l.Integrated)
+	}
+
+	// a part 6 LU down is above the relative gate and counts.
+	writeSine(m, 997, -26, 10)
+
+	if l := m.Loudness; l.Integrated > -24 {
+		t.Errorf("integrated %v LUFS with a louder part, want below -24", l.Integrated)
+	}
+
+	m.Reset()
+
+	if l := m.Loudness; !math.IsInf(l.Integrated, -1) {
+		t.Errorf("integrated %v LUFS after reset, want -Inf", l.Integrated)
+	}
+}
+
+func TestTruePeak(t *testing.T) {
+	var m = New(1, testRate)
+
+	// a sine at a quarter of the rate, 45 degrees off, has every sample at
+	// 1/sqrt(2) of its crests, which fall between samples.
+	var buf = make([]float64, 4800)
+	for idx := range buf {
+		buf[idx] = math.Sin((math.Pi / 2.0 * float64(idx)) + (math.Pi / 4.0))
+	}
+
+	m.Write([][]float64{buf})
+
+	var lv = m.Levels[0]
+
+	if !near(lv.Peak, math.Sqrt2/2.0, 1e-9) {
+		t.Errorf("sample peak %v, want %v", lv.Peak, math.Sqrt2/2.0)
+	}
+
+	if !near(lv.TruePeak, 1.0, 0.05) {
+		t.Errorf("true peak %v, want about 1", lv.TruePeak)
+	}
+
+	if m.MaxTruePeak != lv.TruePeak {
+		t.Errorf("max true peak %v, want %v", m.MaxTruePeak, lv.TruePeak)
+	}
+}
+
+func TestStereo(t *testing.T) {
+	var left, right = make([]float64, 480), make([]float64, 480)
+	for idx := range left {
+		left[idx] = math.Sin(float64(idx) / 5.0)
+	}
+
+	var tests = []struct {
+		name        string
+		right       func(l float64) float64
+		correlation float64
+		balance     float64
+		midSide     float64
+	}{
+		{"mono", func(l float64) float64 { return l }, 1, 0, MaxMidSideRatio},
+		{"anti-phase", func(l float64) float64 { return -l }, -1, 0, -MaxMidSideRatio},
+		{"left only", func(l float64) float64 { return 0 }, 0, -1, 0},
+		{"silence", func(l float64) float64 { return 0 }, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		var l = left
+		if tt.name == "silence" {
+			l = make([]float64, len(left))
+		}
+
+		for idx := range right {
+			right[idx] = tt.right(l[idx])
+		}
+
+		st := MeasureStereo(l, right)
+
+		if !near(st.Correlation, tt.correlation, 1e-9) || !near(st.Balance, tt.balance, 1e-9) {
+			t.Errorf("%s: correlation %v balance %v, want %v and %v",
+				tt.name, st.Correlation, st.Balance, tt.correlation, tt.balance)
+		}
+
+		if ms := st.MidSideRatio(); !near(ms, tt.midSide, 1e-9) {
+			t.Errorf("%s: mid to side ratio %v, want %v", tt.name, ms, tt.midSide)
+		}
+	}
+}
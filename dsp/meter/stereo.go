@@ -0,0 +1,96 @@
+package meter
+
+import (
+	"math"
+)
+
+// Stereo holds the stereo measurements of a single block.
+type Stereo struct {
+	Correlation float64 // [-1, 1], 1 is mono and -1 is phase inverted
+	Balance     float64 // [-1, 1], -1 is left only and 1 is right only
+	Mid         float64 // mean square of (L + R) / 2
+	Side        float64 // mean square of (L - R) / 2
+}
+
+// MeasureStereo measures a block of left and right samples.
+func MeasureStereo(left, right []float64) Stereo {
+	var ll, rr, lr, mid, side float64
+
+	for n := range left {
+		l, r := left[n], right[n]
+
+		ll += l * l
+		rr += r * r
+		lr += l * r
+
+		m, s := (l+r)/2.0, (l-r)/2.0
+		mid += m * m
+		side += s * s
+	}
+
+	var st Stereo
+
+	if size := float64(len(left)); size > 0 {
+		st.Mid = mid / size
+		st.Side = side / size
+	}
+
+	if d := math.Sqrt(ll * rr); d > 0 {
+		st.Correlation = lr / d
+	}
+
+	if sum := ll + rr; sum > 0 {
+		st.Balance = (rr - ll) / sum
+	}
+
+	return st
+}
+
+// MaxMidSideRatio bounds MidSideRatio, as a block of mono has no side at all
+// and one of anti-phase no mid.
+const MaxMidSideRatio = 60.0
+
+// MidSideRatio returns the mid to side energy ratio in decibels, within
+// MaxMidSideRatio. Silence is 0.
+func (st Stereo) MidSideRatio() float64 {
+	switch {
+	case st.Mid <= 0 && st.Side <= 0:
+		return 0
+	case st.Side <= 0:
+		return MaxMidSideRatio
+	case st.Mid <= 0:
+		return -MaxMidSideRatio
+	}
+
+	ratio := 10.0 * math.Log10(st.Mid/st.Side)
+
+	return math.Max(math.Min(ratio, MaxMidSideRatio), -MaxMidSideRatio)
+}
+
+// Width returns the side energy over the total energy. 0 is mono, 0.5 is
+// uncorrelated and 1 is phase inverted.
+func (st Stereo) Width() float64 {
+	return width(st.Mid, st.Side)
+}
+
+// BandWidth returns the width of a band of left and right fft bins, like
+// Stereo.Width does for samples.
+func BandWidth(left, right []complex128) float64 {
+	var mid, side float64
+
+	for k := range left {
+		m, s := left[k]+right[k], left[k]-right[k]
+		mid += (real(m) * real(m)) + (imag(m) * imag(m))
+		side += (real(s) * real(s)) + (imag(s) * imag(s))
+	}
+
+	return width(mid, side)
+}
+
+func width(mid, side float64) float64 {
+	if sum := mid + side; sum > 0 {
+		return side / sum
+	}
+
+	return 0
+}
@@ -0,0 +1,87 @@
+package meter
+
+import (
+	"math"
+)
+
+const (
+	// oversample is the true peak oversampling factor.
+	oversample = 4
+	// peakTaps is the number of filter taps per phase.
+	peakTaps = 12
+)
+
+// peakFilter holds the interpolation filter for each phase. Phase 0 is the
+// original sample.
+var peakFilter = makePeakFilter()
+
+// makePeakFilter builds a Blackman windowed sinc interpolator split into
+// oversample phases, each normalized to unity gain at DC.
+func makePeakFilter() (taps [oversample][peakTaps]float64) {
+	var center = float64(peakTaps / 2)
+	var span = center + 0.5
+
+	for p := range taps {
+		sum := 0.0
+
+		for k := range taps[p] {
+			t := float64(k) - center + (float64(p) / oversample)
+
+			w := 0.42 + (0.5 * math.Cos(math.Pi*t/span)) + (0.08 * math.Cos(2.0*math.Pi*t/span))
+
+			v := w
+			if t != 0.0 {
+				v *= math.Sin(math.Pi*t) / (math.Pi * t)
+			}
+
+			taps[p][k] = v
+			sum += v
+		}
+
+		for k := range taps[p] {
+			taps[p][k] /= sum
+		}
+	}
+
+	return
+}
+
+// truePeak estimates the peak of the reconstructed signal.
+type truePeak struct {
+	history [peakTaps]float64
+	index   int
+}
+
+// tick adds x and returns the highest absolute value between the previous
+// sample and the current one, delayed by half the filter length.
+func (tp *truePeak) tick(x float64) float64 {
+	tp.history[tp.index] = x
+
+	var peak float64
+
+	for p := range peakFilter {
+		var y float64
+
+		// walk backwards from the newest sample.
+		idx := tp.index
+		for _, tap := range peakFilter[p] {
+			y += tap * tp.history[idx]
+
+			if idx--; idx < 0 {
+				idx = peakTaps - 1
+			}
+		}
+
+		peak = math.Max(peak, math.Abs(y))
+	}
+
+	if tp.index++; tp.index >= peakTaps {
+		tp.index = 0
+	}
+
+	return peak
+}
+
+func (tp *truePeak) reset() {
+	*tp = truePeak{}
+}
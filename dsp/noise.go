@@ -0,0 +1,265 @@
+package dsp
+
+import (
+	"math"
+)
+
+// NoiseMode is how the noise floor is removed from a spectrum.
+type NoiseMode int
+
+// noise modes
+const (
+	NoiseOff      NoiseMode = iota // leave the noise in
+	NoiseSubtract                  // subtract the floor from each bin
+	NoiseGate                      // silence bins below the floor
+)
+
+const (
+	// NoiseWindow is how far back we look for the minimum, in seconds.
+	NoiseWindow = 4.0
+	// NoiseLearnTime is how long we listen when learning noise, in seconds.
+	NoiseLearnTime = 2.0
+	// NoiseFactor is the default factor applied to the floor.
+	NoiseFactor = 1.5
+
+	// noiseSubWindows is the number of sub windows in NoiseWindow.
+	noiseSubWindows = 8
+	// noiseSmoothing is the smoothing applied to values before tracking.
+	noiseSmoothing = 0.7
+)
+
+// NoiseFloor estimates the noise floor of every bin using minimum statistics.
+//
+// The floor of a bin is the lowest smoothed magnitude seen over the last
+// NoiseWindow seconds, kept as the minimum of a ring of shorter sub windows
+// so old minimums can expire. A learned floor replaces the tracked one until
+// it is cleared.
+//
+// https://ieeexplore.ieee.org/document/928915
+type NoiseFloor struct {
+	Mode   NoiseMode
+	Factor float64 // factor the floor is multiplied by before it is applied
+
+	bins       int // number of bins per channel
+	subFrames  int // number of frames in a sub window
+	learnTotal int // number of frames to learn for
+
+	frame int // frame in the current sub window
+	index int // index of the current sub window
+	learn int // frames left to learn, if learning
+
+	learned bool // the floor was learned and is not tracked
+
+	channels []noiseChannel
+}
+
+type noiseChannel struct {
+	smooth  []float64 // smoothed magnitudes
+	current []float64 // minimums of the current sub window
+	mins    []float64 // minimums of each sub window, by sub window
+	stored  []float64 // minimums of all finished sub windows
+	floor   []float64 // the floor
+	sums    []float64 // learning sums
+}
+
+// Init allocates the floor for channels with up to maxBins bins each, at
+// frameRate frames per second.
+func (nf *NoiseFloor) Init(channels, maxBins int, frameRate float64) {
+	if nf.Factor <= 0.0 {
+		nf.Factor = NoiseFactor
+	}
+
+	nf.subFrames = int(math.Max((NoiseWindow*frameRate)/noiseSubWindows, 1))
+	nf.learnTotal = int(math.Max(NoiseLearnTime*frameRate, 1))
+
+	nf.channels = make([]noiseChannel, channels)
+	for idx := range nf.channels {
+		buf := make([]float64, maxBins*(noiseSubWindows+5))
+
+		nf.channels[idx] = noiseChannel{
+			smooth:  buf[:maxBins],
+			current: buf[maxBins : maxBins*2],
+			stored:  buf[maxBins*2 : maxBins*3],
+			floor:   buf[maxBins*3 : maxBins*4],
+			sums:    buf[maxBins*4 : maxBins*5],
+			mins:    buf[maxBins*5:],
+		}
+	}
+
+	nf.Reset(maxBins)
+}
+
+// Reset forgets everything and starts tracking bins bins per channel.
+func (nf *NoiseFloor) Reset(bins int) {
+	nf.bins = bins
+	nf.frame = 0
+	nf.index = 0
+	nf.learn = 0
+	nf.learned = false
+
+	for _, ch := range nf.channels {
+		for idx := range ch.smooth {
+			ch.smooth[idx] = 0
+			ch.current[idx] = math.Inf(1)
+			ch.stored[idx] = math.Inf(1)
+			ch.floor[idx] = 0
+			ch.sums[idx] = 0
+		}
+
+		for idx := range ch.mins {
+			ch.mins[idx] = math.Inf(1)
+		}
+	}
+}
+
+// Remap keeps the floor when the number of bins changes but the bins still
+// cover the same fft indexes. New bin idx takes over old bin from[idx].
+func (nf *NoiseFloor) Remap(from []int) {
+	var bins = len(from)
+
+	for _, c := range nf.channels {
+		old := make([]float64, len(c.mins))
+
+		for _, buf := range [][]float64{c.smooth, c.current, c.stored, c.floor, c.sums} {
+			copy(old, buf[:nf.bins])
+			for idx, src := range from {
+				buf[idx] = old[src]
+			}
+		}
+
+		copy(old, c.mins[:noiseSubWindows*nf.bins])
+		for win := 0; win < noiseSubWindows; win++ {
+			for idx, src := range from {
+				c.mins[(win*bins)+idx] = old[(win*nf.bins)+src]
+			}
+		}
+	}
+
+	nf.bins = bins
+}
+
+// Learn starts learning the floor from the next NoiseLearnTime seconds, which
+// should be nothing but noise. The learned floor is kept until Forget.
+func (nf *NoiseFloor) Learn() {
+	nf.learn = nf.learnTotal
+
+	for _, ch := range nf.channels {
+		for idx := range ch.sums {
+			ch.sums[idx] = 0
+		}
+	}
+}
+
+// Forget drops a learned floor and goes back to tracking.
+func (nf *NoiseFloor) Forget() {
+	nf.Reset(nf.bins)
+}
+
+// Learning returns true while learning.
+func (nf *NoiseFloor) Learning() bool {
+	return nf.learn > 0
+}
+
+// Apply tracks mag for bin idx of channel ch and returns it with the floor
+// removed according to Mode.
+func (nf *NoiseFloor) Apply(ch, idx int, mag float64) float64 {
+	var c = &nf.channels[ch]
+
+	switch {
+	case nf.learn > 0:
+		c.sums[idx] += mag
+
+	case !nf.learned:
+		s := mag
+		if c.smooth[idx] > 0.0 {
+			s = (c.smooth[idx] * noiseSmoothing) + (mag * (1.0 - noiseSmoothing))
+		}
+		c.smooth[idx] = s
+
+		if s < c.current[idx] {
+			c.current[idx] = s
+		}
+
+		c.floor[idx] = math.Min(c.stored[idx], c.current[idx])
+	}
+
+	var floor = c.floor[idx] * nf.Factor
+
+	switch nf.Mode {
+	case NoiseSubtract:
+		return math.Max(mag-floor, 0.0)
+
+	case NoiseGate:
+		if mag < floor {
+			return 0.0
+		}
+	}
+
+	return mag
+}
+
+// Process implements Stage. It applies the floor to the bars in use and ends
+// the frame.
+func (nf *NoiseFloor) Process(f *Frame) {
+	if nf.Mode == NoiseOff {
+		return
+	}
+
+	for ch, buf := range f.Bars[:f.Channels] {
+		for idx, mag := range buf[:f.Count] {
+			buf[idx] = nf.Apply(ch, idx, mag)
+		}
+	}
+
+	nf.Advance()
+}
+
+// Advance ends a frame. It must be called once after every bin of every
+// channel has been applied.
+func (nf *NoiseFloor) Advance() {
+	if nf.learn > 0 {
+		if nf.learn--; nf.learn == 0 {
+			nf.finishLearning()
+		}
+		return
+	}
+
+	if nf.learned {
+		return
+	}
+
+	if nf.frame++; nf.frame < nf.subFrames {
+		return
+	}
+
+	nf.frame = 0
+
+	for _, c := range nf.channels {
+		// store the finished sub window and rebuild the floor from all of them.
+		copy(c.mins[nf.index*nf.bins:(nf.index+1)*nf.bins], c.current[:nf.bins])
+
+		for idx := range c.stored[:nf.bins] {
+			stored := math.Inf(1)
+			for win := 0; win < noiseSubWindows; win++ {
+				stored = math.Min(stored, c.mins[(win*nf.bins)+idx])
+			}
+
+			c.stored[idx] = stored
+			c.current[idx] = math.Inf(1)
+		}
+	}
+
+	if nf.index++; nf.index >= noiseSubWindows {
+		nf.index = 0
+	}
+}
+
+func (nf *NoiseFloor) finishLearning() {
+	for _, c := range nf.channels {
+		for idx := range c.floor[:nf.bins] {
+			c.floor[idx] = c.sums[idx] / float64(nf.learnTotal)
+		}
+	}
+
+	nf.learned = true
+}
@@ -0,0 +1,169 @@
+package dsp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+const noiseTestRate = 20.0 // frames per second
+
+// noiseFrame returns the magnitudes of a frame: noise in [1, 1.5) in every
+// bin and a tone of 10 in bin 1 that is on for a second out of every two.
+func noiseFrame(rng *rand.Rand, frame int) []float64 {
+	var mags = []float64{1 + (rng.Float64() / 2), 1 + (rng.Float64() / 2)}
+
+	if (frame/int(noiseTestRate))%2 == 1 {
+		mags[1] += 10
+	}
+
+	return mags
+}
+
+func TestNoiseFloorTracks(t *testing.T) {
+	var nf = NoiseFloor{Mode: NoiseSubtract, Factor: 1}
+	var rng = rand.New(rand.NewSource(1))
+
+	nf.Init(1, 2, noiseTestRate)
+
+	var frames = int(4 * NoiseWindow * noiseTestRate)
+
+	for frame := 0; frame < frames; frame++ {
+		for idx, mag := range noiseFrame(rng, frame) {
+			out := nf.Apply(0, idx, mag)
+
+			if out < 0 {
+				t.Fatalf("frame %d bin %d: %v went negative", frame, idx, out)
+			}
+
+			// once the floor has settled, the tone is all that is left.
+			if idx == 1 && frame > frames/2 && mag > 10 && (out < 9 || out > 10.5) {
+				t.Errorf("frame %d: tone of %v came out as %v", frame, mag, out)
+			}
+		}
+
+		nf.Advance()
+	}
+
+	// the floor sits between the lowest and the mean noise, with the tone
+	// ignored.
+	for idx, floor := range nf.channels[0].floor[:2] {
+		if floor < 1.0 || floor > 1.25 {
+			t.Errorf("bin %d: floor %v, want the noise level in [1, 1.25]", idx, floor)
+		}
+	}
+}
+
+func TestNoiseFloorGate(t *testing.T) {
+	var nf = NoiseFloor{Mode: NoiseGate, Factor: 1}
+	var rng = rand.New(rand.NewSource(2))
+
+	nf.Init(1, 2, noiseTestRate)
+
+	for frame := 0; frame < int(2*NoiseWindow*noiseTestRate); frame++ {
+		for idx, mag := range noiseFrame(rng, frame) {
+			if out := nf.Apply(0, idx, mag); out != 0 && out != mag {
+				t.Fatalf("frame %d bin %d: gate changed %v to %v", frame, idx, mag, out)
+			}
+		}
+
+		nf.Advance()
+	}
+
+	// well above the floor passes, below it does not.
+	if out := nf.Apply(0, 0, 5); out != 5 {
+		t.Errorf("gate passed %v, want 5", out)
+	}
+
+	if out := nf.Apply(0, 0, 0.5); out != 0 {
+		t.Errorf("gate passed %v, want 0", out)
+	}
+}
+
+func TestNoiseFloorLearn(t *testing.T) {
+	var nf = NoiseFloor{Mode: NoiseSubtract, Factor: 1}
+
+	nf.Init(1, 1, noiseTestRate)
+	nf.Learn()
+
+	// the learned floor is the mean of what was heard.
+	for frame := 0; nf.Learning(); frame++ {
+		nf.Apply(0, 0, float64(1+(frame%2)))
+		nf.Advance()
+	}
+
+	if floor := nf.channels[0].floor[0]; floor != 1.5 {
+		t.Fatalf("learned floor %v, want 1.5", floor)
+	}
+
+	// a learned floor is not tracked.
+	for frame := 0; frame < int(2*NoiseWindow*noiseTestRate); frame++ {
+		nf.Apply(0, 0, 0.1)
+		nf.Advance()
+	}
+
+	if out := nf.Apply(0, 0, 4); out != 2.5 {
+		t.Errorf("got %v, want 2.5 with the learned floor", out)
+	}
+
+	nf.Forget()
+
+	if out := nf.Apply(0, 0, 4); out != 0 {
+		t.Errorf("got %v after forgetting, want 0 with the tracked floor", out)
+	}
+}
+
+func TestNoiseFloorRecalculate(t *testing.T) {
+	var sp = Spectrum{
+		SampleRate: testRate,
+		SampleSize: testSize,
+		Bins:       make([]Bin, testSize),
+	}
+
+	sp.Noise.Mode = NoiseSubtract
+	sp.Noise.Factor = 1
+	sp.Noise.Init(1, testSize, noiseTestRate)
+
+	var bars = sp.Recalculate(32)
+	var oldRanges = make([][2]int, bars)
+	for idx := range oldRanges {
+		oldRanges[idx][0], oldRanges[idx][1] = sp.BinRange(idx)
+	}
+
+	// learn a different floor for every bar.
+	for sp.Noise.Learn(); sp.Noise.Learning(); sp.Noise.Advance() {
+		for idx := 0; idx < bars; idx++ {
+			sp.Noise.Apply(0, idx, float64(idx+1))
+		}
+	}
+
+	bars = sp.Recalculate(bars + 1)
+
+	// every new bar keeps the floor of the old bar it overlaps.
+	for idx := 0; idx < bars; idx++ {
+		lo, hi := sp.BinRange(idx)
+		mid := (lo + hi) / 2
+
+		var want = -1.0
+		for old, r := range oldRanges {
+			if r[0] <= mid && mid < r[1] {
+				want = float64(old + 1)
+			}
+		}
+
+		if want < 0 {
+			continue
+		}
+
+		if out := sp.Noise.Apply(0, idx, 100); out != 100-want {
+			t.Errorf("bar %d: got %v, want %v with the learned floor", idx, out, 100-want)
+		}
+	}
+
+	// a new fft size starts over.
+	sp.Resize(testSize)
+	sp.Recalculate(bars)
+
+	if out := sp.Noise.Apply(0, 0, 100); out != 0 {
+		t.Errorf("got %v after a resize, want 0 with the tracked floor", out)
+	}
+}
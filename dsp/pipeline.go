@@ -0,0 +1,161 @@
+package dsp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// stage names
+const (
+	StageFilter    = "filter" // time-domain filters on the input
+	StageWindow    = "window" // window function on the input
+	StageTransform = "fft"    // input to spectrum
+	StageBin       = "bin"    // spectrum to bars
+	StageNoise     = "noise"  // noise floor removal
+	StageWeight    = "weight" // per bar weighting
+	StageSmooth    = "smooth" // time smoothing
+	StageScale     = "scale"  // scaling
+	StagePeak      = "peak"   // peak tracking
+)
+
+// DefaultStages is the default order of stages.
+var DefaultStages = []string{
+	StageFilter,
+	StageWindow,
+	StageTransform,
+	StageBin,
+	StageNoise,
+	StageWeight,
+	StageSmooth,
+	StageScale,
+}
+
+// Frame holds the buffers passed through a Pipeline. The buffers are
+// allocated once and reused for every frame.
+type Frame struct {
+	Input    [][]float64    // time-domain samples for each channel
+	Spectrum [][]complex128 // transform output for each channel
+	Bars     [][]float64    // bar values for each channel
+	Peaks    [][]float64    // peak values for each channel
+	Fresh    int            // number of samples at the end of input new to this frame
+	Channels int            // number of channels of bars in use
+	Count    int            // number of bars in use per channel
+	Scale    float64        // bar value drawn as a full bar
+}
+
+// NewFrame allocates a frame for channels channels of size samples, which
+// can be resized up to maxSize samples.
+func NewFrame(channels, size, maxSize int) *Frame {
+	var (
+		fftSize = maxSize/2 + 1
+
+		floatData   = make([]float64, channels*maxSize*3)
+		complexData = make([]complex128, channels*fftSize)
+	)
+
+	var f = &Frame{
+		Input:    make([][]float64, channels),
+		Spectrum: make([][]complex128, channels),
+		Bars:     make([][]float64, channels),
+		Peaks:    make([][]float64, channels),
+		Fresh:    size,
+		Channels: channels,
+		Scale:    1.0,
+	}
+
+	for idx := 0; idx < channels; idx++ {
+		f.Input[idx], floatData = floatData[:maxSize:maxSize], floatData[maxSize:]
+		f.Bars[idx], floatData = floatData[:maxSize], floatData[maxSize:]
+		f.Peaks[idx], floatData = floatData[:maxSize], floatData[maxSize:]
+		f.Spectrum[idx], complexData = complexData[:fftSize:fftSize], complexData[fftSize:]
+	}
+
+	f.Resize(size)
+
+	return f
+}
+
+// Resize changes the number of input samples to size. It does not allocate
+// unless size is larger than the frame was made for.
+func (f *Frame) Resize(size int) {
+	for idx := range f.Input {
+		if cap(f.Input[idx]) < size {
+			f.Input[idx] = make([]float64, size)
+			f.Spectrum[idx] = make([]complex128, size/2+1)
+		}
+
+		f.Input[idx] = f.Input[idx][:size]
+		f.Spectrum[idx] = f.Spectrum[idx][:size/2+1]
+	}
+}
+
+// Stage is a single step of a Pipeline.
+type Stage interface {
+	// Process processes a frame in place. It must not allocate.
+	Process(f *Frame)
+}
+
+// StageFunc is a function that is a Stage.
+type StageFunc func(f *Frame)
+
+// Process calls fn.
+func (fn StageFunc) Process(f *Frame) {
+	fn(f)
+}
+
+// Pipeline runs a frame through stages in order.
+type Pipeline struct {
+	Stages []Stage
+}
+
+// NewPipeline returns a pipeline of the stages named in order. Names map to
+// nil for stages that do not apply to this pipeline, which are skipped.
+func NewPipeline(order []string, stages map[string]Stage) (*Pipeline, error) {
+	var p = &Pipeline{}
+
+	for _, name := range order {
+		stage, ok := stages[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown stage %q", name)
+		}
+
+		if stage != nil {
+			p.Stages = append(p.Stages, stage)
+		}
+	}
+
+	return p, nil
+}
+
+// ParseStages splits a comma separated list of stage names. Every stage may
+// only be named once, and peaks are tracked after scaling.
+func ParseStages(list string) ([]string, error) {
+	var names = strings.Split(list, ",")
+	var seen = make(map[string]bool, len(names))
+
+	for idx := range names {
+		names[idx] = strings.TrimSpace(names[idx])
+
+		if seen[names[idx]] {
+			return nil, fmt.Errorf("stage %q named twice", names[idx])
+		}
+		seen[names[idx]] = true
+
+		if names[idx] == StageScale && seen[StagePeak] {
+			return nil, fmt.Errorf("stage %q must come after %q", StagePeak, StageScale)
+		}
+	}
+
+	return names, nil
+}
+
+// Process runs f through every stage. The frame starts with all channels in
+// use and no scaling.
+func (p *Pipeline) Process(f *Frame) {
+	f.Channels = len(f.Input)
+	f.Scale = 1.0
+
+	for _, stage := range p.Stages {
+		stage.Process(f)
+	}
+}
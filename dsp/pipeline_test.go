@@ -0,0 +1,197 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/noriah/catnip/dsp/filter"
+	"github.com/noriah/catnip/dsp/scale"
+	"github.com/noriah/catnip/dsp/window"
+	"github.com/noriah/catnip/fft"
+)
+
+const (
+	testSize = 1024
+	testRate = 44100.0
+)
+
+// sine fills buf with a full scale sine of freq Hz.
+func sine(buf []float64, freq float64) {
+	for idx := range buf {
+		buf[idx] = math.Sin(2.0 * math.Pi * freq * float64(idx) / testRate)
+	}
+}
+
+func TestNewPipeline(t *testing.T) {
+	var ran []string
+	var stage = func(name string) Stage {
+		return StageFunc(func(*Frame) { ran = append(ran, name) })
+	}
+
+	var stages = map[string]Stage{
+		"a": stage("a"),
+		"b": nil,
+		"c": stage("c"),
+	}
+
+	p, err := NewPipeline([]string{"c", "b", "a"}, stages)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.Process(NewFrame(1, 8, 8))
+
+	if len(ran) != 2 || ran[0] != "c" || ran[1] != "a" {
+		t.Errorf("ran %v, want [c a]", ran)
+	}
+
+	if _, err := NewPipeline([]string{"a", "x"}, stages); err == nil {
+		t.Error("unknown stage did not fail")
+	}
+}
+
+func TestParseStages(t *testing.T) {
+	names, err := ParseStages(" window, fft ,bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 3 || names[0] != "window" || names[1] != "fft" || names[2] != "bin" {
+		t.Errorf("parsed %q", names)
+	}
+
+	for _, list := range []string{"fft,bin,fft", "bin,peak,scale"} {
+		if _, err := ParseStages(list); err == nil {
+			t.Errorf("parsed %q without an error", list)
+		}
+	}
+
+	if _, err := ParseStages("bin,scale,peak"); err != nil {
+		t.Errorf("peak after scale: %v", err)
+	}
+}
+
+func TestFold(t *testing.T) {
+	var f = NewFrame(2, 4, 4)
+	f.Count = 2
+	f.Bars[0][0], f.Bars[0][1] = 2, 4
+	f.Bars[1][0], f.Bars[1][1] = 4, 8
+
+	Fold(f)
+
+	if f.Channels != 1 || f.Bars[0][0] != 3 || f.Bars[0][1] != 6 {
+		t.Errorf("folded to %d channels %v", f.Channels, f.Bars[0][:2])
+	}
+}
+
+func TestSmoother(t *testing.T) {
+	var s = NewSmoother(1, 1)
+	s.smoothScale = 0.5
+
+	var f = NewFrame(1, 1, 1)
+	f.Count = 1
+
+	for _, want := range []float64{4, 6, 7} {
+		f.Bars[0][0] = 8
+		s.Process(f)
+
+		if f.Bars[0][0] != want {
+			t.Errorf("smoothed to %v, want %v", f.Bars[0][0], want)
+		}
+	}
+}
+
+func TestPeakHold(t *testing.T) {
+	var p = NewPeakHold(1, 1, 10)
+	var f = NewFrame(1, 1, 1)
+	f.Count = 1
+
+	f.Bars[0][0] = 10
+	p.Process(f)
+
+	f.Bars[0][0] = 0
+	for frame := 0; frame < p.Hold; frame++ {
+		p.Process(f)
+		if f.Peaks[0][0] != 10 {
+			t.Fatalf("frame %d: peak fell to %v while held", frame, f.Peaks[0][0])
+		}
+	}
+
+	p.Process(f)
+	if want := 10 - p.Fall; math.Abs(f.Peaks[0][0]-want) > 1e-9 {
+		t.Errorf("peak fell to %v, want %v", f.Peaks[0][0], want)
+	}
+}
+
+func TestSpectrumStages(t *testing.T) {
+	var f = NewFrame(1, testSize, testSize)
+	var sp = Spectrum{
+		SampleRate: testRate,
+		SampleSize: testSize,
+		Bins:       make([]Bin, testSize),
+	}
+
+	var bars = sp.Recalculate(32)
+
+	sine(f.Input[0], 1000)
+	NewTransform(f, fft.KindAuto).Process(f)
+	sp.Bin(f)
+
+	if f.Count != bars {
+		t.Fatalf("binned %d bars, want %d", f.Count, bars)
+	}
+
+	// the loudest bar holds 1 kHz.
+	var loudest int
+	for idx, v := range f.Bars[0][:f.Count] {
+		if v > f.Bars[0][loudest] {
+			loudest = idx
+		}
+	}
+
+	lo, hi := sp.BinRange(loudest)
+	if freq := 1000 * float64(testSize) / testRate; freq < float64(lo)-1 || freq > float64(hi)+1 {
+		t.Errorf("loudest bar %d covers fft bins [%d, %d), want %v", loudest, lo, hi, freq)
+	}
+}
+
+func TestPipelineAllocs(t *testing.T) {
+	var f = NewFrame(2, testSize, testSize)
+	var sp = Spectrum{
+		SampleRate: testRate,
+		SampleSize: testSize,
+		Bins:       make([]Bin, testSize),
+	}
+
+	sp.Noise.Mode = NoiseSubtract
+	sp.Noise.Init(2, testSize, testRate/testSize)
+	sp.Recalculate(64)
+
+	var smoother = NewSmoother(2, testSize)
+	smoother.SetSmoothing(0.5, testSize, testRate)
+
+	p, err := NewPipeline(append(DefaultStages, StagePeak), map[string]Stage{
+		StageFilter:    NewFilter(filter.Chain{filter.NewHighPass(20, filter.ButterworthQ, testRate)}, 2, testSize),
+		StageWindow:    Window{Func: window.Blackman},
+		StageTransform: NewTransform(f, fft.KindAuto),
+		StageBin:       StageFunc(sp.Bin),
+		StageNoise:     &sp.Noise,
+		StageWeight:    StageFunc(sp.Weight),
+		StageSmooth:    smoother,
+		StageScale:     Scale{Scaler: scale.NewWindow(testRate / testSize)},
+		StagePeak:      NewPeakHold(2, testSize, testRate/testSize),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var allocs = testing.AllocsPerRun(100, func() {
+		sine(f.Input[0], 440)
+		sine(f.Input[1], 880)
+		p.Process(f)
+	})
+
+	if allocs != 0 {
+		t.Errorf("pipeline allocated %v times per frame", allocs)
+	}
+}
@@ -0,0 +1,55 @@
+package scale
+
+import (
+	"math"
+)
+
+const (
+	// AGCAttack is how long the AGC takes to follow a rising peak, in seconds.
+	AGCAttack = 0.05
+	// AGCRelease is how long the AGC takes to follow a falling peak, in
+	// seconds.
+	AGCRelease = 3.0
+)
+
+// AGC follows the peak with a fast attack and a slow release.
+type AGC struct {
+	Attack  float64 // per frame coefficient for rising peaks
+	Release float64 // per frame coefficient for falling peaks
+
+	envelope float64
+}
+
+// NewAGC returns an AGC with the default attack and release times for
+// frameRate frames per second.
+func NewAGC(frameRate float64) *AGC {
+	return &AGC{
+		Attack:  Coefficient(AGCAttack, frameRate),
+		Release: Coefficient(AGCRelease, frameRate),
+	}
+}
+
+// Coefficient returns the per frame coefficient of a one pole follower that
+// reaches about 63% of a step in seconds.
+func Coefficient(seconds, frameRate float64) float64 {
+	if seconds <= 0.0 || frameRate <= 0.0 {
+		return 1.0
+	}
+
+	return 1.0 - math.Exp(-1.0/(seconds*frameRate))
+}
+
+// Scale implements Scaler.
+func (a *AGC) Scale(bufs [][]float64, count int) float64 {
+	a.envelope = follow(a.envelope, Peak(bufs, count), a.Attack, a.Release)
+	return math.Max(a.envelope, Floor)
+}
+
+// follow moves envelope towards peak.
+func follow(envelope, peak, attack, release float64) float64 {
+	if peak > envelope {
+		return envelope + ((peak - envelope) * attack)
+	}
+
+	return envelope + ((peak - envelope) * release)
+}
@@ -0,0 +1,55 @@
+package scale
+
+import (
+	"math"
+)
+
+// Band follows the peak of every band on its own and normalizes each band to
+// its peak, so quiet bands are drawn as high as loud ones. Channels share
+// the peak of a band to keep their balance.
+type Band struct {
+	Attack  float64 // per frame coefficient for rising peaks
+	Release float64 // per frame coefficient for falling peaks
+
+	envelopes []float64
+	count     int
+}
+
+// NewBand returns a Band for frameRate frames per second and up to maxBands
+// bands, with the default AGC attack and release times.
+func NewBand(frameRate float64, maxBands int) *Band {
+	return &Band{
+		Attack:    Coefficient(AGCAttack, frameRate),
+		Release:   Coefficient(AGCRelease, frameRate),
+		envelopes: make([]float64, maxBands),
+	}
+}
+
+// Scale implements Scaler. It scales the values in place and always
+// returns 1.
+func (b *Band) Scale(bufs [][]float64, count int) float64 {
+	// bands moved, start over.
+	if count != b.count {
+		b.count = count
+		for idx := range b.envelopes {
+			b.envelopes[idx] = 0
+		}
+	}
+
+	for idx, envelope := range b.envelopes[:count] {
+		var peak float64
+		for _, buf := range bufs {
+			peak = math.Max(peak, buf[idx])
+		}
+
+		envelope = follow(envelope, peak, b.Attack, b.Release)
+		b.envelopes[idx] = envelope
+
+		scale := math.Max(envelope, Floor)
+		for _, buf := range bufs {
+			buf[idx] /= scale
+		}
+	}
+
+	return 1.0
+}
@@ -0,0 +1,32 @@
+package scale
+
+import (
+	"math"
+)
+
+const (
+	// ManualReference is the value drawn as a full bar at 0 dB gain. It is
+	// about the peak of a loud track with the default settings.
+	ManualReference = 100.0
+	// ManualStep is the gain step of Adjust, in decibels.
+	ManualStep = 1.0
+	// ManualMaxGain is the gain that scales ManualReference down to Floor, in
+	// decibels. More gain would not change anything.
+	ManualMaxGain = 40.0
+)
+
+// Manual scales by a fixed gain.
+type Manual struct {
+	Gain float64 // gain in decibels
+}
+
+// Scale implements Scaler. It never returns less than Floor, however much
+// gain is set.
+func (m *Manual) Scale(bufs [][]float64, count int) float64 {
+	return math.Max(ManualReference/math.Pow(10.0, m.Gain/20.0), Floor)
+}
+
+// Adjust changes the gain by steps of ManualStep, up to ManualMaxGain.
+func (m *Manual) Adjust(steps int) {
+	m.Gain = math.Min(m.Gain+(float64(steps)*ManualStep), ManualMaxGain)
+}
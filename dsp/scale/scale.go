@@ -0,0 +1,58 @@
+// Package scale provides strategies for scaling bars to the screen
+package scale
+
+// Scaler decides how far bars are scaled down before they are drawn.
+type Scaler interface {
+	// Scale looks at the first count values of each buffer and returns the
+	// value that should be drawn as a full bar. It may also adjust the values
+	// in place. It is called once per frame.
+	Scale(bufs [][]float64, count int) float64
+}
+
+// Kind identifies a Scaler implementation.
+type Kind int
+
+// scaler kinds
+const (
+	KindWindow Kind = iota // mean and standard deviation of recent peaks
+	KindAGC                // peak follower with attack and release
+	KindManual             // fixed gain
+	KindBand               // every band normalized on its own
+	KindMax
+)
+
+// Floor is the lowest value any scaler returns, so silence is not
+// amplified into noise.
+const Floor = 1.0
+
+// New returns a new Scaler of kind for frameRate frames per second and up
+// to maxBands values per buffer. It returns nil for an unknown kind.
+func New(kind Kind, frameRate float64, maxBands int) Scaler {
+	switch kind {
+	case KindWindow:
+		return NewWindow(frameRate)
+	case KindAGC:
+		return NewAGC(frameRate)
+	case KindManual:
+		return &Manual{}
+	case KindBand:
+		return NewBand(frameRate, maxBands)
+	default:
+		return nil
+	}
+}
+
+// Peak returns the highest of the first count values of each buffer.
+func Peak(bufs [][]float64, count int) float64 {
+	var peak float64
+
+	for _, buf := range bufs {
+		for _, v := range buf[:count] {
+			if peak < v {
+				peak = v
+			}
+		}
+	}
+
+	return peak
+}
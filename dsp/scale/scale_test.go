@@ -0,0 +1,188 @@
+package scale
+
+import (
+	"math"
+	"testing"
+)
+
+// frameRate is about 44100 / 1024.
+const frameRate = 43.0
+
+// feed runs s over frames frames of bufs filled with values from gen.
+func feed(s Scaler, bufs [][]float64, frames int, gen func(frame, band int) float64) float64 {
+	var scale float64
+
+	for frame := 0; frame < frames; frame++ {
+		for _, buf := range bufs {
+			for band := range buf {
+				buf[band] = gen(frame, band)
+			}
+		}
+
+		scale = s.Scale(bufs, len(bufs[0]))
+	}
+
+	return scale
+}
+
+func makeBufs(channels, bands int) [][]float64 {
+	var bufs = make([][]float64, channels)
+	for idx := range bufs {
+		bufs[idx] = make([]float64, bands)
+	}
+	return bufs
+}
+
+func constant(v float64) func(int, int) float64 {
+	return func(int, int) float64 { return v }
+}
+
+func TestNew(t *testing.T) {
+	for kind := KindWindow; kind < KindMax; kind++ {
+		if New(kind, frameRate, 16) == nil {
+			t.Errorf("New(%d) returned nil", kind)
+		}
+	}
+
+	if New(KindMax, frameRate, 16) != nil {
+		t.Error("New(KindMax) did not return nil")
+	}
+}
+
+func TestScalersFloor(t *testing.T) {
+	for kind := KindWindow; kind < KindMax; kind++ {
+		if kind == KindManual {
+			continue
+		}
+
+		s := New(kind, frameRate, 16)
+		if v := feed(s, makeBufs(2, 16), 100, constant(0)); v != Floor {
+			t.Errorf("kind %d: silence scaled to %v, want %v", kind, v, Floor)
+		}
+	}
+}
+
+func TestWindow(t *testing.T) {
+	var w = NewWindow(frameRate)
+	var bufs = makeBufs(2, 8)
+
+	// a steady peak leaves a little headroom.
+	v := feed(w, bufs, int(SlowWindow*frameRate), constant(50))
+	if v < 50 || v > 60 {
+		t.Errorf("steady peak scaled to %v, want in [50, 60]", v)
+	}
+
+	// a quiet passage is followed within the window capacity.
+	v = feed(w, bufs, int(SlowWindow*frameRate)*2, constant(5))
+	if v > 25 {
+		t.Errorf("quiet passage scaled to %v, want below 25", v)
+	}
+
+	// peaks that vary are scaled above their mean.
+	v = feed(w, bufs, int(SlowWindow*frameRate)*2, func(frame, band int) float64 {
+		return 40 + (20 * float64(frame%2))
+	})
+	if v <= 50 || v > 70 {
+		t.Errorf("varying peak scaled to %v, want in (50, 70]", v)
+	}
+}
+
+func TestAGC(t *testing.T) {
+	var a = NewAGC(frameRate)
+	var bufs = makeBufs(1, 4)
+
+	// reaching about 63% after the attack time.
+	v := feed(a, bufs, int(math.Round(AGCAttack*frameRate)), constant(100))
+	if v < 50 || v > 80 {
+		t.Errorf("after attack scaled to %v, want about 63", v)
+	}
+
+	v = feed(a, bufs, int(frameRate), constant(100))
+	if math.Abs(v-100) > 1 {
+		t.Errorf("after a second scaled to %v, want 100", v)
+	}
+
+	// releasing slowly.
+	v = feed(a, bufs, int(math.Round(frameRate*AGCRelease*0.1)), constant(10))
+	if v < 80 {
+		t.Errorf("released to %v too fast", v)
+	}
+
+	v = feed(a, bufs, int(frameRate*AGCRelease*5), constant(10))
+	if math.Abs(v-10) > 1 {
+		t.Errorf("after release scaled to %v, want 10", v)
+	}
+}
+
+func TestManual(t *testing.T) {
+	var m = &Manual{}
+	var bufs = makeBufs(1, 4)
+
+	if v := feed(m, bufs, 1, constant(1000)); v != ManualReference {
+		t.Errorf("0 dB scaled to %v, want %v", v, ManualReference)
+	}
+
+	m.Adjust(6)
+	if v := feed(m, bufs, 1, constant(0)); math.Abs(v-(ManualReference/2)) > 0.5 {
+		t.Errorf("+6 dB scaled to %v, want about %v", v, ManualReference/2)
+	}
+
+	m.Adjust(-12)
+	if v := feed(m, bufs, 1, constant(0)); math.Abs(v-(ManualReference*2)) > 1 {
+		t.Errorf("-6 dB scaled to %v, want about %v", v, ManualReference*2)
+	}
+
+	// gain past the floor is not kept, a step down is heard right away.
+	m.Adjust(1000)
+	if v := feed(m, bufs, 1, constant(0)); v != Floor {
+		t.Errorf("+%v dB scaled to %v, want %v", m.Gain, v, Floor)
+	}
+
+	m.Adjust(-1)
+	if v := feed(m, bufs, 1, constant(0)); v <= Floor {
+		t.Errorf("%v dB scaled to %v, want above %v", m.Gain, v, Floor)
+	}
+
+	// a gain set directly is clamped as well.
+	m.Gain = 200
+	if v := feed(m, bufs, 1, constant(0)); v != Floor {
+		t.Errorf("+200 dB scaled to %v, want %v", v, Floor)
+	}
+}
+
+func TestBand(t *testing.T) {
+	var b = NewBand(frameRate, 16)
+	var bufs = makeBufs(2, 4)
+
+	// every band an order of magnitude above the last.
+	v := feed(b, bufs, int(frameRate*2), func(frame, band int) float64 {
+		return 10 * math.Pow(10, float64(band))
+	})
+
+	if v != 1.0 {
+		t.Errorf("band scaler returned %v, want 1", v)
+	}
+
+	for ch, buf := range bufs {
+		for band, value := range buf {
+			if math.Abs(value-1.0) > 0.01 {
+				t.Errorf("channel %d band %d normalized to %v, want 1", ch, band, value)
+			}
+		}
+	}
+
+	// channels keep their balance.
+	for frame := 0; frame < int(frameRate); frame++ {
+		for band := range bufs[0] {
+			bufs[0][band] = 100
+			bufs[1][band] = 50
+		}
+		b.Scale(bufs, 4)
+	}
+
+	for band := range bufs[0] {
+		if ratio := bufs[1][band] / bufs[0][band]; math.Abs(ratio-0.5) > 1e-9 {
+			t.Errorf("band %d channel ratio %v, want 0.5", band, ratio)
+		}
+	}
+}
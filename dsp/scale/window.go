@@ -0,0 +1,77 @@
+package scale
+
+import (
+	"math"
+
+	"github.com/noriah/catnip/util"
+)
+
+const (
+	// SlowWindow in seconds
+	SlowWindow = 5
+	// FastWindow in seconds
+	FastWindow = SlowWindow * 0.2
+	// DumpPercent is how much we erase on rescale
+	DumpPercent = 0.60
+	// ResetDeviation standard deviations from the mean before reset
+	ResetDeviation = 1.0
+	// PeakThreshold is the threshold to not draw if the peak is less.
+	PeakThreshold = 0.01
+)
+
+// Window scales to the mean plus 1.5 standard deviations of the peaks over
+// the last SlowWindow seconds.
+//
+// A second window over the last FastWindow seconds tells us when the level
+// changed, like going into a quiet passage. When the fast mean is more than
+// ResetDeviation standard deviations away from the slow mean, most of the
+// slow window is dropped so the scale follows quickly instead of taking the
+// whole SlowWindow to catch up.
+type Window struct {
+	slow util.MovingWindow
+	fast util.MovingWindow
+}
+
+// NewWindow returns a Window for frameRate frames per second.
+func NewWindow(frameRate float64) *Window {
+	// leave room for frames drawn without new input.
+	var slowMax = int(SlowWindow*frameRate) * 2
+	var fastMax = int(FastWindow*frameRate) * 2
+
+	var data = make([]float64, slowMax+fastMax)
+
+	return &Window{
+		slow: util.MovingWindow{
+			Capacity: slowMax,
+			Data:     data[:slowMax],
+		},
+		fast: util.MovingWindow{
+			Capacity: fastMax,
+			Data:     data[slowMax:],
+		},
+	}
+}
+
+// Scale implements Scaler.
+func (w *Window) Scale(bufs [][]float64, count int) float64 {
+	var peak = Peak(bufs, count)
+
+	// do some scaling if we are above the PeakThreshold
+	if peak < PeakThreshold {
+		return Floor
+	}
+
+	w.fast.Update(peak)
+	vMean, vSD := w.slow.Update(peak)
+
+	// if our slow window finally has more values than our fast window
+	if length := w.slow.Len(); length >= w.fast.Cap() {
+		// the level moved away from what the slow window remembers
+		if math.Abs(w.fast.Mean()-vMean) > (ResetDeviation * vSD) {
+			// drop some values and continue
+			vMean, vSD = w.slow.Drop(int(float64(length) * DumpPercent))
+		}
+	}
+
+	return math.Max(vMean+(1.5*vSD), Floor)
+}
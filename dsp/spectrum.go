@@ -17,15 +17,13 @@ import (
 
 // Spectrum is an audio spectrum in a buffer
 type Spectrum struct {
-	Bins         []Bin       // bins for processing
-	SampleSize   int         // number of samples per slice
-	binCount     int         // number of bins we look at
-	fftSize      int         // number of fft bins
-	OldValues    [][]float64 // old values used for smoothing
-	SampleRate   float64     // audio sample rate
-	winVar       float64     // window variable
-	smoothFactor float64     // smothing factor
-	smoothScale  float64     // smoothing pow
+	Bins       []Bin      // bins for processing
+	SampleSize int        // number of samples per slice
+	binCount   int        // number of bins we look at
+	fftSize    int        // number of fft bins
+	Noise      NoiseFloor // noise floor removed before smoothing
+	SampleRate float64    // audio sample rate
+	winVar     float64    // window variable
 }
 
 // Bin is a helper struct for spectrum
@@ -59,8 +57,8 @@ func (sp *Spectrum) BinCount() int {
 	return sp.binCount
 }
 
-func (sp *Spectrum) ProcessBin(ch, idx int, src []complex128) float64 {
-	mag := 0.0
+// BinRange returns the range of fft indexes [floor, ceil) in bin idx.
+func (sp *Spectrum) BinRange(idx int) (int, int) {
 	bin := sp.Bins[idx]
 
 	fftFloor, fftCeil := bin.floorFFT, bin.ceilFFT
@@ -68,24 +66,60 @@ func (sp *Spectrum) ProcessBin(ch, idx int, src []complex128) float64 {
 		fftCeil = sp.fftSize
 	}
 
-	src = src[fftFloor:fftCeil]
-	for _, cmplx := range src {
-		power := math.Hypot(real(cmplx), imag(cmplx))
-		if mag < power {
-			mag = power
+	return fftFloor, fftCeil
+}
+
+// BinFrequencies returns the range of frequencies [low, high) in bin idx in
+// Hz.
+func (sp *Spectrum) BinFrequencies(idx int) (float64, float64) {
+	floor, ceil := sp.BinRange(idx)
+	res := sp.SampleRate / float64(sp.SampleSize)
+
+	return float64(floor) * res, float64(ceil) * res
+}
+
+// Bin fills the bars in use with the peak magnitude of each bin.
+func (sp *Spectrum) Bin(f *Frame) {
+	f.Count = sp.binCount
+
+	for ch, buf := range f.Bars[:f.Channels] {
+		src := f.Spectrum[ch]
+
+		for idx := range buf[:f.Count] {
+			fftFloor, fftCeil := sp.BinRange(idx)
+
+			mag := 0.0
+			for _, cmplx := range src[fftFloor:fftCeil] {
+				power := math.Hypot(real(cmplx), imag(cmplx))
+				if mag < power {
+					mag = power
+				}
+			}
+
+			buf[idx] = mag
 		}
 	}
+}
 
-	// time smoothing
-	mag = math.Pow(mag, bin.powVal) * (1.0 - sp.smoothScale)
-
-	value := (sp.OldValues[ch][idx] * sp.smoothScale) + mag
-	sp.OldValues[ch][idx] = value
+// Weight applies the power and equalizer of each bin to the bars in use.
+func (sp *Spectrum) Weight(f *Frame) {
+	for _, buf := range f.Bars[:f.Channels] {
+		for idx, bin := range sp.Bins[:f.Count] {
+			buf[idx] = math.Pow(buf[idx], bin.powVal) * bin.eqVal
+		}
+	}
+}
 
-	return value * bin.eqVal
+// Resize changes the number of samples per slice. The bins are rebuilt and
+// the noise floor is reset by the next Recalculate.
+func (sp *Spectrum) Resize(size int) {
+	sp.SampleSize = size
+	sp.fftSize = size/2 + 1
+	sp.binCount = 0
 }
 
-// Recalculate rebuilds our frequency bins
+// Recalculate rebuilds our frequency bins. The noise floor is carried over
+// from the old bins, unless the fft size changed.
 func (sp *Spectrum) Recalculate(binCount int) int {
 	if sp.fftSize == 0 {
 		sp.fftSize = sp.SampleSize/2 + 1
@@ -98,6 +132,12 @@ func (sp *Spectrum) Recalculate(binCount int) int {
 		return binCount
 	}
 
+	// remember where the old bins ended to carry the noise floor over.
+	var oldCeils = make([]int, sp.binCount)
+	for idx, b := range sp.Bins[:sp.binCount] {
+		oldCeils[idx] = b.ceilFFT
+	}
+
 	sp.binCount = binCount
 
 	// clean the binCount
@@ -127,9 +167,36 @@ func (sp *Spectrum) Recalculate(binCount int) int {
 
 	}
 
+	sp.remapNoise(oldCeils)
+
 	return binCount
 }
 
+// remapNoise moves the noise floor of the old bins, which ended at oldCeils,
+// to the new ones. Each new bin takes the floor of the old bin holding its
+// middle fft index. Without old bins the fft size changed and the floor is
+// reset.
+func (sp *Spectrum) remapNoise(oldCeils []int) {
+	if len(oldCeils) == 0 {
+		sp.Noise.Reset(sp.binCount)
+		return
+	}
+
+	var from = make([]int, sp.binCount)
+	var src = 0
+
+	for idx, b := range sp.Bins[:sp.binCount] {
+		mid := (b.floorFFT + b.ceilFFT) / 2
+		for src < len(oldCeils)-1 && oldCeils[src] <= mid {
+			src++
+		}
+
+		from[idx] = src
+	}
+
+	sp.Noise.Remap(from)
+}
+
 func (sp *Spectrum) distribute(bins int) {
 	var lo = Frequencies[1]
 	var hi = math.Min(sp.SampleRate/2, Frequencies[4])
@@ -182,15 +249,14 @@ func (sp *Spectrum) SetWinVar(g float64) {
 	sp.winVar = g
 }
 
-// SetSmoothing sets the smoothing parameters
-func (sp *Spectrum) SetSmoothing(factor float64) {
+// smoothScale converts a smoothing factor into the per-frame decay used for
+// time smoothing at the given sample size and rate.
+func smoothScale(factor float64, size int, rate float64) float64 {
 	if factor <= 0.0 {
 		factor = math.SmallestNonzeroFloat64
 	}
 
-	sp.smoothFactor = factor
-
 	var sf = math.Pow(10.0, (1.0-factor)*(-25.0))
 
-	sp.smoothScale = math.Pow(sf, float64(sp.SampleSize)/sp.SampleRate)
+	return math.Pow(sf, float64(size)/rate)
 }
@@ -0,0 +1,254 @@
+package dsp
+
+import (
+	"math"
+
+	"github.com/noriah/catnip/dsp/filter"
+	"github.com/noriah/catnip/dsp/scale"
+	"github.com/noriah/catnip/dsp/window"
+	"github.com/noriah/catnip/fft"
+)
+
+// Filter runs the input of every channel through a chain of filters.
+//
+// The filters keep their state between frames and only filter the fresh
+// samples of a frame. The rest of the input is replaced with what was
+// filtered before.
+type Filter struct {
+	Chains []filter.Chain // filters of each channel
+
+	filtered [][]float64 // the latest filtered samples of each channel
+}
+
+// NewFilter returns a filter stage running the chain on channels channels of
+// up to maxSize samples.
+func NewFilter(chain filter.Chain, channels, maxSize int) *Filter {
+	var f = &Filter{
+		Chains:   make([]filter.Chain, channels),
+		filtered: make([][]float64, channels),
+	}
+
+	for idx := range f.Chains {
+		f.Chains[idx] = append(filter.Chain(nil), chain...)
+		f.filtered[idx] = make([]float64, maxSize)
+	}
+
+	return f
+}
+
+// Process implements Stage.
+func (fl *Filter) Process(f *Frame) {
+	for ch, buf := range f.Input[:f.Channels] {
+		chain := fl.Chains[ch]
+		if len(chain) == 0 {
+			continue
+		}
+
+		fresh := f.Fresh
+		if fresh > len(buf) {
+			fresh = len(buf)
+		}
+
+		chain.Process(buf[len(buf)-fresh:])
+
+		filtered := fl.filtered[ch]
+		shift(filtered, buf[len(buf)-fresh:])
+		copy(buf, filtered[len(filtered)-len(buf):])
+	}
+}
+
+// Window applies a window function to the input of every channel.
+type Window struct {
+	Func window.Function
+}
+
+// Process implements Stage.
+func (w Window) Process(f *Frame) {
+	for _, buf := range f.Input[:f.Channels] {
+		w.Func(buf)
+	}
+}
+
+// Transform runs an fft plan from the input to the spectrum of every
+// channel.
+type Transform struct {
+	Plans []*fft.Plan
+}
+
+// NewTransform creates and initializes plans of kind for the buffers of f.
+func NewTransform(f *Frame, kind fft.Kind) *Transform {
+	var t = &Transform{
+		Plans: make([]*fft.Plan, len(f.Input)),
+	}
+
+	for idx := range t.Plans {
+		t.Plans[idx] = &fft.Plan{
+			Input:  f.Input[idx],
+			Output: f.Spectrum[idx],
+			Kind:   kind,
+		}
+
+		t.Plans[idx].Init()
+	}
+
+	return t
+}
+
+// Process implements Stage.
+func (t *Transform) Process(f *Frame) {
+	for _, plan := range t.Plans[:f.Channels] {
+		plan.Execute()
+	}
+}
+
+// Resize replans for the buffers of f after it was resized.
+func (t *Transform) Resize(f *Frame) {
+	for idx, plan := range t.Plans {
+		plan.Input, plan.Output = f.Input[idx], f.Spectrum[idx]
+		plan.Resize(len(f.Input[idx]))
+	}
+}
+
+// Close releases the plans.
+func (t *Transform) Close() {
+	for _, plan := range t.Plans {
+		plan.Close()
+	}
+}
+
+// Fold averages the bars of all channels into the first channel.
+func Fold(f *Frame) {
+	if f.Channels < 2 {
+		return
+	}
+
+	var dst = f.Bars[0][:f.Count]
+
+	for _, buf := range f.Bars[1:f.Channels] {
+		for idx, v := range buf[:f.Count] {
+			dst[idx] += v
+		}
+	}
+
+	for idx := range dst {
+		dst[idx] /= float64(f.Channels)
+	}
+
+	f.Channels = 1
+}
+
+// Smoother smooths the bars over time.
+type Smoother struct {
+	OldValues   [][]float64 // old values used for smoothing
+	smoothScale float64     // smoothing pow
+}
+
+// NewSmoother allocates a smoother for channels channels of up to size bars.
+func NewSmoother(channels, size int) *Smoother {
+	var s = &Smoother{
+		OldValues: make([][]float64, channels),
+	}
+
+	for idx := range s.OldValues {
+		s.OldValues[idx] = make([]float64, size)
+	}
+
+	return s
+}
+
+// SetSmoothing sets the smoothing parameters
+func (s *Smoother) SetSmoothing(factor float64, sampleSize int, sampleRate float64) {
+	s.smoothScale = smoothScale(factor, sampleSize, sampleRate)
+}
+
+// Process implements Stage.
+func (s *Smoother) Process(f *Frame) {
+	for ch, buf := range f.Bars[:f.Channels] {
+		old := s.OldValues[ch]
+
+		for idx, v := range buf[:f.Count] {
+			value := (old[idx] * s.smoothScale) + (v * (1.0 - s.smoothScale))
+			old[idx] = value
+			buf[idx] = value
+		}
+	}
+}
+
+// Scale sets the frame scale with a scaler.
+type Scale struct {
+	Scaler scale.Scaler
+}
+
+// Process implements Stage.
+func (s Scale) Process(f *Frame) {
+	f.Scale = s.Scaler.Scale(f.Bars[:f.Channels], f.Count)
+}
+
+const (
+	// PeakHoldTime is how long a peak is held before it falls, in seconds.
+	PeakHoldTime = 0.5
+	// PeakFallTime is how long a peak takes to fall a full bar, in seconds.
+	PeakFallTime = 1.0
+)
+
+// PeakHold tracks the peak of every bar. Peaks are held for a while and then
+// fall at a rate relative to the frame scale, so it should come after
+// scaling.
+type PeakHold struct {
+	Hold int     // number of frames a peak is held
+	Fall float64 // part of the scale a peak falls every frame
+
+	holds [][]int
+	count int
+}
+
+// NewPeakHold allocates a peak tracker for channels channels of up to size
+// bars at frameRate frames per second.
+func NewPeakHold(channels, size int, frameRate float64) *PeakHold {
+	var p = &PeakHold{
+		Hold:  int(math.Round(PeakHoldTime * frameRate)),
+		Fall:  1.0 / math.Max(PeakFallTime*frameRate, 1.0),
+		holds: make([][]int, channels),
+	}
+
+	for idx := range p.holds {
+		p.holds[idx] = make([]int, size)
+	}
+
+	return p
+}
+
+// Process implements Stage.
+func (p *PeakHold) Process(f *Frame) {
+	// the bars moved, start over.
+	if f.Count != p.count {
+		p.count = f.Count
+
+		for ch := range p.holds {
+			for idx := range p.holds[ch] {
+				p.holds[ch][idx] = 0
+				f.Peaks[ch][idx] = 0
+			}
+		}
+	}
+
+	var fall = p.Fall * f.Scale
+
+	for ch, buf := range f.Bars[:f.Channels] {
+		peaks, holds := f.Peaks[ch], p.holds[ch]
+
+		for idx, v := range buf[:f.Count] {
+			switch {
+			case v >= peaks[idx]:
+				peaks[idx] = v
+				holds[idx] = p.Hold
+
+			case holds[idx] > 0:
+				holds[idx]--
+
+			default:
+				peaks[idx] = math.Max(peaks[idx]-fall, v)
+			}
+		}
+	}
+}
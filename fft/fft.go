@@ -1,2 +1,171 @@
 // Package fft provides generic abstractions around fourier transformers.
 package fft
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Planner is how hard FFTW tries to find a fast plan. Harder planners take
+// longer to start, unless the plans are already in the wisdom.
+type Planner int
+
+// planners
+const (
+	PlannerEstimate Planner = iota // guess a plan without running anything
+	PlannerMeasure                 // time a few plans and pick the fastest
+	PlannerPatient                 // time many more plans
+
+	PlannerMax
+)
+
+// Kind is an fft implementation.
+type Kind int
+
+// kinds
+const (
+	KindAuto         Kind = iota // FFTW with cgo, else split radix or gonum by size
+	KindFFTW                     // FFTW, only when built with cgo
+	KindGonum                    // gonum
+	KindSplitRadix               // pure go split radix
+	KindSplitRadix32             // pure go split radix in float32
+
+	KindMax
+)
+
+// Plan is a real fft of Input into Output. Output has len(Input)/2+1 values.
+type Plan struct {
+	Input  []float64
+	Output []complex128
+	Kind   Kind // implementation used by Init
+	impl   impl
+}
+
+// impl is an fft implementation bound to the buffers of a plan.
+type impl interface {
+	execute()
+	close()
+}
+
+// Init sets up the plan so we dont run checks during execute
+func (p *Plan) Init() {
+	if p.impl == nil {
+		p.impl = newImpl(p.Kind, p.Input, p.Output, false)
+	}
+}
+
+// Execute runs the plan
+func (p *Plan) Execute() {
+	p.impl.execute()
+}
+
+// Resize replans for n samples. Input and Output are resliced to n and
+// n/2+1 values, and reallocated if they are too small.
+//
+// Resizing happens while running, so FFTW only uses the planner if the
+// wisdom already has a plan for n and estimates one otherwise.
+func (p *Plan) Resize(n int) {
+	p.Close()
+	p.Input, p.Output = resize(p.Input, p.Output, n)
+	p.impl = newImpl(p.Kind, p.Input, p.Output, true)
+}
+
+// Close releases the plan. The plan can be used again after Init.
+func (p *Plan) Close() {
+	if p.impl != nil {
+		p.impl.close()
+		p.impl = nil
+	}
+}
+
+// newImpl returns the implementation kind for in and out. Without FFTW, the
+// split radix is used instead. The split radix only works on powers of two
+// and falls back to gonum for other sizes. Quick
+// plans are made without timing anything.
+func newImpl(kind Kind, in []float64, out []complex128, quick bool) impl {
+	if kind == KindAuto || kind == KindFFTW {
+		if impl := newFFTW(in, out, quick); impl != nil {
+			return impl
+		}
+		kind = KindSplitRadix
+	}
+
+	if isPow2(len(in)) {
+		switch kind {
+		case KindSplitRadix:
+			return newSplitRadix(in, out)
+		case KindSplitRadix32:
+			return newSplitRadix32(in, out)
+		}
+	}
+
+	return newGonum(in, out)
+}
+
+func isPow2(n int) bool {
+	return n > 1 && n&(n-1) == 0
+}
+
+// resize reslices input to n values and output to n/2+1 values, allocating
+// new buffers if they are too small.
+func resize(input []float64, output []complex128, n int) ([]float64, []complex128) {
+	if cap(input) < n {
+		input = make([]float64, n)
+	}
+
+	if cap(output) < n/2+1 {
+		output = make([]complex128, n/2+1)
+	}
+
+	return input[:n], output[:n/2+1]
+}
+
+// WisdomPath returns the path of the wisdom cache file.
+func WisdomPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find the cache directory")
+	}
+
+	return filepath.Join(dir, "catnip", "fftw.wisdom"), nil
+}
+
+// LoadWisdom adds the wisdom saved at path to the planner, so plans found
+// before do not have to be found again. A missing file is not an error. It
+// must be called before plans are made and does nothing without FFTW.
+func LoadWisdom(path string) error {
+	if !FFTW {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to read wisdom")
+	}
+
+	return importWisdom(string(data))
+}
+
+// SaveWisdom saves the wisdom of the planner to path, creating directories
+// as needed. It does nothing without FFTW.
+func SaveWisdom(path string) error {
+	if !FFTW {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create the wisdom directory")
+	}
+
+	if err := ioutil.WriteFile(path, []byte(exportWisdom()), 0644); err != nil {
+		return errors.Wrap(err, "failed to write wisdom")
+	}
+
+	return nil
+}
@@ -1,12 +1,17 @@
 package fft
 
-import "testing"
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
 
 func Benchmark(b *testing.B) {
 	if FFTW {
 		b.Log("Benchmarking FFTW.")
 	} else {
-		b.Log("Benchmarking gonum (built without cgo).")
+		b.Log("Benchmarking the split radix (built without cgo).")
 	}
 
 	reals := generateReals()
@@ -16,6 +21,9 @@ func Benchmark(b *testing.B) {
 		Output: cmplx,
 	}
 
+	fftpl.Init()
+	defer fftpl.Close()
+
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
@@ -23,6 +31,119 @@ func Benchmark(b *testing.B) {
 	}
 }
 
+// kindNames are the names of the kinds in benchmarks.
+var kindNames = [KindMax]string{
+	KindAuto:         "auto",
+	KindFFTW:         "fftw",
+	KindGonum:        "gonum",
+	KindSplitRadix:   "splitradix",
+	KindSplitRadix32: "splitradix32",
+}
+
+func BenchmarkKinds(b *testing.B) {
+	for kind := KindFFTW; kind < KindMax; kind++ {
+		if kind == KindFFTW && !FFTW {
+			continue
+		}
+
+		for _, size := range []int{256, 1024, 4096, 16384} {
+			b.Run(fmt.Sprintf("%s/%d", kindNames[kind], size), func(b *testing.B) {
+				var plan = Plan{
+					Input:  randomReals(size, 1),
+					Output: make([]complex128, size/2+1),
+					Kind:   kind,
+				}
+
+				plan.Init()
+				defer plan.Close()
+
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					plan.Execute()
+				}
+			})
+		}
+	}
+}
+
+func TestSplitRadix(t *testing.T) {
+	var tests = []struct {
+		kind      Kind
+		tolerance float64 // relative to the largest magnitude
+	}{
+		{KindSplitRadix, 1e-12},
+		{KindSplitRadix32, 1e-5},
+	}
+
+	for _, test := range tests {
+		for size := 2; size <= 8192; size *= 2 {
+			var input = randomReals(size, int64(size))
+
+			want := transform(KindGonum, input)
+			got := transform(test.kind, input)
+
+			if err := maxError(got, want); err > test.tolerance {
+				t.Errorf("%s size %d: error %g, want below %g",
+					kindNames[test.kind], size, err, test.tolerance)
+			}
+		}
+	}
+}
+
+func TestSplitRadixFallback(t *testing.T) {
+	var input = randomReals(1000, 1)
+
+	want := transform(KindGonum, input)
+	got := transform(KindSplitRadix, input)
+
+	if err := maxError(got, want); err > 1e-12 {
+		t.Errorf("size 1000: error %g", err)
+	}
+}
+
+// transform returns the spectrum of input from a plan of kind. The input is
+// copied, as plans may change their input while planning.
+func transform(kind Kind, input []float64) []complex128 {
+	var plan = Plan{
+		Input:  make([]float64, len(input)),
+		Output: make([]complex128, len(input)/2+1),
+		Kind:   kind,
+	}
+
+	plan.Init()
+	defer plan.Close()
+
+	copy(plan.Input, input)
+	plan.Execute()
+
+	return plan.Output
+}
+
+// maxError returns the largest difference between a and b relative to the
+// largest magnitude of b.
+func maxError(a, b []complex128) float64 {
+	var diff, peak float64
+
+	for idx := range b {
+		diff = math.Max(diff, math.Hypot(real(a[idx]-b[idx]), imag(a[idx]-b[idx])))
+		peak = math.Max(peak, math.Hypot(real(b[idx]), imag(b[idx])))
+	}
+
+	return diff / math.Max(peak, 1)
+}
+
+func randomReals(size int, seed int64) []float64 {
+	var r = rand.New(rand.NewSource(seed))
+
+	var input = make([]float64, size)
+	for idx := range input {
+		input[idx] = (r.Float64() * 2) - 1
+	}
+
+	return input
+}
+
 // Adapted from https://github.com/project-gemmi/benchmarking-fft/blob/master/1d-r.cpp
 
 const numReals = 44100
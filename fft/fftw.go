@@ -8,44 +8,100 @@ package fft
 // implement here.
 
 // #cgo pkg-config: fftw3
+// #include <stdlib.h>
 // #include <fftw3.h>
 import "C"
 
 import (
 	"runtime"
 	"unsafe"
+
+	"github.com/pkg/errors"
 )
 
 // FFTW is true if Catnip is built with cgo.
 const FFTW = true
 
-// Plan holds an FFTW C plan
-type Plan struct {
-	Input  []float64
-	Output []complex128
-	cPlan  C.fftw_plan
+// planFlags are the flags new plans are made with.
+var planFlags C.uint = C.FFTW_MEASURE
+
+// fftw holds an FFTW C plan
+type fftw struct {
+	cPlan C.fftw_plan
 }
 
-// Init sets up the plan so we dont run checks during execute
-func (p *Plan) Init() {
-	if p.cPlan == nil {
-		p.cPlan = C.fftw_plan_dft_r2c_1d(
-			C.int(len(p.Input)),
-			(*C.double)(unsafe.Pointer(&p.Input[0])),
-			(*C.fftw_complex)(unsafe.Pointer(&p.Output[0])),
-			C.FFTW_MEASURE,
-		)
+// newFFTW plans for in and out. Quick plans come from the wisdom, or are
+// estimated if it has none.
+func newFFTW(in []float64, out []complex128, quick bool) impl {
+	var cPlan C.fftw_plan
+
+	switch {
+	case !quick || planFlags == C.FFTW_ESTIMATE:
+		cPlan = planR2C(in, out, planFlags)
 
-		runtime.SetFinalizer(p, (*Plan).destroy)
+	default:
+		if cPlan = planR2C(in, out, planFlags|C.FFTW_WISDOM_ONLY); cPlan == nil {
+			cPlan = planR2C(in, out, C.FFTW_ESTIMATE)
+		}
 	}
+
+	var p = &fftw{cPlan: cPlan}
+
+	// in case the plan is never closed.
+	runtime.SetFinalizer(p, (*fftw).destroy)
+
+	return p
 }
 
-// Execute runs the plan
-func (p *Plan) Execute() {
+func planR2C(in []float64, out []complex128, flags C.uint) C.fftw_plan {
+	return C.fftw_plan_dft_r2c_1d(
+		C.int(len(in)),
+		(*C.double)(unsafe.Pointer(&in[0])),
+		(*C.fftw_complex)(unsafe.Pointer(&out[0])),
+		flags,
+	)
+}
+
+func (p *fftw) execute() {
 	C.fftw_execute(p.cPlan)
 }
 
+func (p *fftw) close() {
+	runtime.SetFinalizer(p, nil)
+	p.destroy()
+}
+
 // destroy releases resources
-func (p *Plan) destroy() {
+func (p *fftw) destroy() {
 	C.fftw_destroy_plan(p.cPlan)
 }
+
+// SetPlanner sets the planner used by plans made after it.
+func SetPlanner(planner Planner) {
+	switch planner {
+	case PlannerEstimate:
+		planFlags = C.FFTW_ESTIMATE
+	case PlannerPatient:
+		planFlags = C.FFTW_PATIENT
+	default:
+		planFlags = C.FFTW_MEASURE
+	}
+}
+
+func importWisdom(wisdom string) error {
+	var cWisdom = C.CString(wisdom)
+	defer C.free(unsafe.Pointer(cWisdom))
+
+	if C.fftw_import_wisdom_from_string(cWisdom) == 0 {
+		return errors.New("invalid wisdom")
+	}
+
+	return nil
+}
+
+func exportWisdom() string {
+	var cWisdom = C.fftw_export_wisdom_to_string()
+	defer C.free(unsafe.Pointer(cWisdom))
+
+	return C.GoString(cWisdom)
+}
@@ -0,0 +1,16 @@
+// +build cgo,!fftwthreads
+
+package fft
+
+import (
+	"github.com/pkg/errors"
+)
+
+// SetThreads fails for more than one thread, as catnip was built without
+// fftw threads. Build with -tags fftwthreads to use them.
+func SetThreads(threads int) error {
+	if threads > 1 {
+		return errors.New("built without fftw threads")
+	}
+	return nil
+}
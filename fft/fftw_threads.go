@@ -0,0 +1,26 @@
+// +build cgo,fftwthreads
+
+package fft
+
+// #cgo LDFLAGS: -lfftw3_threads -lpthread
+// #include <fftw3.h>
+import "C"
+
+import (
+	"github.com/pkg/errors"
+)
+
+var threadsReady bool
+
+// SetThreads sets the number of threads used by plans made after it.
+func SetThreads(threads int) error {
+	if !threadsReady {
+		if C.fftw_init_threads() == 0 {
+			return errors.New("failed to initialize fftw threads")
+		}
+		threadsReady = true
+	}
+
+	C.fftw_plan_with_nthreads(C.int(threads))
+	return nil
+}
@@ -1,29 +1,26 @@
-// +build !cgo
-
 package fft
 
 import (
 	"gonum.org/v1/gonum/dsp/fourier"
 )
 
-// FFTW is false if Catnip is not built with cgo. It will use gonum instead.
-const FFTW = false
-
-// Plan holds a gonum FFT plan.
-type Plan struct {
-	Input  []float64
-	Output []complex128
+// gonum is a gonum FFT plan.
+type gonum struct {
+	input  []float64
+	output []complex128
 	fft    *fourier.FFT
 }
 
-// Init sets up the plan so we dont run checks during execute
-func (p *Plan) Init() {
-	if p.fft == nil {
-		p.fft = fourier.NewFFT(len(p.Input))
+func newGonum(in []float64, out []complex128) *gonum {
+	return &gonum{
+		input:  in,
+		output: out,
+		fft:    fourier.NewFFT(len(in)),
 	}
 }
 
-// Execute executes the gonum plan.
-func (p *Plan) Execute() {
-	p.fft.Coefficients(p.Output, p.Input)
+func (g *gonum) execute() {
+	g.fft.Coefficients(g.output, g.input)
 }
+
+func (g *gonum) close() {}
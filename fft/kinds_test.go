@@ -0,0 +1,236 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+	"testing/quick"
+)
+
+// testSizes are powers of two and odd sizes. Odd sizes check the fallback of
+// the split radix as well.
+var testSizes = []int{2, 3, 8, 15, 64, 100, 127, 256, 1000, 1024, 4096}
+
+// tolerances are the largest errors relative to the largest magnitude.
+var tolerances = [KindMax]float64{
+	KindAuto:         1e-9,
+	KindFFTW:         1e-9,
+	KindGonum:        1e-9,
+	KindSplitRadix:   1e-9,
+	KindSplitRadix32: 1e-5,
+}
+
+// kinds returns the kinds available in this build.
+func kinds() []Kind {
+	var list []Kind
+
+	for kind := KindAuto; kind < KindMax; kind++ {
+		if kind == KindFFTW && !FFTW {
+			continue
+		}
+		list = append(list, kind)
+	}
+
+	return list
+}
+
+var inputs = []struct {
+	name string
+	gen  func(size int) []float64
+}{
+	{"sine", func(size int) []float64 {
+		var buf = make([]float64, size)
+		for idx := range buf {
+			buf[idx] = math.Sin(2.0 * math.Pi * float64(size/3) * float64(idx) / float64(size))
+		}
+		return buf
+	}},
+	{"impulse", func(size int) []float64 {
+		var buf = make([]float64, size)
+		buf[size/2] = 1.0
+		return buf
+	}},
+	{"dc", func(size int) []float64 {
+		var buf = make([]float64, size)
+		for idx := range buf {
+			buf[idx] = 0.5
+		}
+		return buf
+	}},
+	{"noise", func(size int) []float64 {
+		return randomReals(size, int64(size))
+	}},
+}
+
+// dft is the definition of the fft, slow and simple.
+func dft(input []float64) []complex128 {
+	var size = len(input)
+	var out = make([]complex128, size/2+1)
+
+	for k := range out {
+		var sum complex128
+		for n, x := range input {
+			// reduce the angle first to keep it precise.
+			angle := -2.0 * math.Pi * float64((k*n)%size) / float64(size)
+			sum += complex(x, 0) * cmplx.Rect(1, angle)
+		}
+		out[k] = sum
+	}
+
+	return out
+}
+
+func TestKindsMatchDFT(t *testing.T) {
+	for _, size := range testSizes {
+		for _, in := range inputs {
+			input := in.gen(size)
+			want := dft(input)
+
+			for _, kind := range kinds() {
+				got := transform(kind, input)
+
+				if err := maxError(got, want); err > tolerances[kind] {
+					t.Errorf("%s size %d %s: error %g, want below %g",
+						kindNames[kind], size, in.name, err, tolerances[kind])
+				}
+			}
+		}
+	}
+}
+
+func TestKindsMatchEachOther(t *testing.T) {
+	for _, size := range []int{2048, 8192, 6000, 16383} {
+		input := randomReals(size, 7)
+		want := transform(KindGonum, input)
+
+		for _, kind := range kinds() {
+			got := transform(kind, input)
+
+			if err := maxError(got, want); err > tolerances[kind] {
+				t.Errorf("%s size %d: error %g against gonum, want below %g",
+					kindNames[kind], size, err, tolerances[kind])
+			}
+		}
+	}
+}
+
+func TestSineBin(t *testing.T) {
+	const size, bin = 1024, 100
+
+	var input = make([]float64, size)
+	for idx := range input {
+		input[idx] = math.Cos(2.0 * math.Pi * bin * float64(idx) / size)
+	}
+
+	for _, kind := range kinds() {
+		out := transform(kind, input)
+
+		for k, v := range out {
+			want := 0.0
+			if k == bin {
+				want = size / 2
+			}
+
+			if math.Abs(cmplx.Abs(v)-want) > 1e-3 {
+				t.Errorf("%s: bin %d magnitude %v, want %v", kindNames[kind], k, cmplx.Abs(v), want)
+			}
+		}
+	}
+}
+
+// energy returns the energy of a full spectrum from its first half.
+func energy(out []complex128, size int) float64 {
+	var sum float64
+
+	for k, v := range out {
+		e := real(v)*real(v) + imag(v)*imag(v)
+
+		// the other half mirrors everything but dc and nyquist.
+		if k != 0 && !(size%2 == 0 && k == size/2) {
+			e *= 2
+		}
+
+		sum += e
+	}
+
+	return sum / float64(size)
+}
+
+func TestParseval(t *testing.T) {
+	for _, kind := range kinds() {
+		kind := kind
+
+		property := func(seed int64, sizeIdx uint8) bool {
+			size := testSizes[int(sizeIdx)%len(testSizes)]
+			input := randomReals(size, seed)
+
+			var want float64
+			for _, x := range input {
+				want += x * x
+			}
+
+			got := energy(transform(kind, input), size)
+			return math.Abs(got-want) <= tolerances[kind]*10*math.Max(want, 1)
+		}
+
+		if err := quick.Check(property, &quick.Config{MaxCount: 200}); err != nil {
+			t.Errorf("%s: %v", kindNames[kind], err)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	for _, kind := range kinds() {
+		var plan = Plan{
+			Input:  make([]float64, 64),
+			Output: make([]complex128, 33),
+			Kind:   kind,
+		}
+
+		plan.Init()
+
+		for _, size := range []int{128, 100, 32, 4096} {
+			plan.Resize(size)
+
+			if len(plan.Input) != size || len(plan.Output) != size/2+1 {
+				t.Fatalf("%s: resized to %d and %d values, want %d and %d",
+					kindNames[kind], len(plan.Input), len(plan.Output), size, size/2+1)
+			}
+
+			input := randomReals(size, 3)
+			want := dft(input)
+
+			copy(plan.Input, input)
+			plan.Execute()
+
+			if err := maxError(plan.Output, want); err > tolerances[kind] {
+				t.Errorf("%s: resized to %d, error %g", kindNames[kind], size, err)
+			}
+		}
+
+		plan.Close()
+	}
+}
+
+func TestAutoWithoutFFTW(t *testing.T) {
+	if FFTW {
+		t.Skip("built with FFTW")
+	}
+
+	for _, size := range []int{64, 100} {
+		var plan = Plan{
+			Input:  make([]float64, size),
+			Output: make([]complex128, size/2+1),
+		}
+
+		plan.Init()
+
+		// the split radix for powers of two, gonum for the rest.
+		_, split := plan.impl.(*splitRadix)
+		if split != isPow2(size) {
+			t.Errorf("size %d: picked %T", size, plan.impl)
+		}
+
+		plan.Close()
+	}
+}
@@ -0,0 +1,28 @@
+// +build !cgo
+
+package fft
+
+// FFTW is false if Catnip is not built with cgo. It will use the split
+// radix instead, or gonum for sizes that are not a power of two.
+const FFTW = false
+
+// newFFTW returns nil, as there is no FFTW.
+func newFFTW(in []float64, out []complex128, quick bool) impl {
+	return nil
+}
+
+// SetPlanner does nothing. There is no FFTW to plan.
+func SetPlanner(planner Planner) {}
+
+// SetThreads does nothing. Plans run on the calling goroutine.
+func SetThreads(threads int) error {
+	return nil
+}
+
+func importWisdom(wisdom string) error {
+	return nil
+}
+
+func exportWisdom() string {
+	return ""
+}
@@ -0,0 +1,140 @@
+package fft
+
+import (
+	"math"
+	"math/bits"
+)
+
+// splitRadix is a pure go real fft for powers of two.
+//
+// The real input is packed into a complex input of half the size, which is
+// transformed with a recursive split radix fft and unpacked into the
+// spectrum of the real input. Twiddles are computed once per plan.
+//
+// https://en.wikipedia.org/wiki/Split-radix_FFT_algorithm
+type splitRadix struct {
+	input  []float64
+	output []complex128
+
+	twiddles [][]complex128 // w^k and w^3k interleaved, by log2 of the size
+	unpack   []complex128   // exp(-2πik/n) for the real size n, k < n/2
+	packed   []complex128   // even samples as real, odd samples as imaginary
+	half     []complex128   // fft of packed
+}
+
+func newSplitRadix(in []float64, out []complex128) *splitRadix {
+	var size = len(in)
+
+	var s = &splitRadix{
+		input:    in,
+		output:   out,
+		twiddles: make([][]complex128, bits.Len(uint(size))),
+		unpack:   make([]complex128, size/2),
+		packed:   make([]complex128, size/2),
+		half:     make([]complex128, size/2),
+	}
+
+	var twiddle = func(k, n int) complex128 {
+		sin, cos := math.Sincos(-2.0 * math.Pi * float64(k) / float64(n))
+		return complex(cos, sin)
+	}
+
+	for k := range s.unpack {
+		s.unpack[k] = twiddle(k, size)
+	}
+
+	for n := 8; n <= size/2; n *= 2 {
+		tw := make([]complex128, n/2)
+		for k := 0; k < n/4; k++ {
+			tw[2*k] = twiddle(k, n)
+			tw[(2*k)+1] = twiddle(3*k, n)
+		}
+
+		s.twiddles[bits.Len(uint(n))] = tw
+	}
+
+	return s
+}
+
+func (s *splitRadix) execute() {
+	var half = len(s.half)
+
+	for idx := range s.packed {
+		s.packed[idx] = complex(s.input[2*idx], s.input[(2*idx)+1])
+	}
+
+	s.transform(s.half, s.packed, 1)
+
+	var z = s.half
+
+	s.output[0] = complex(real(z[0])+imag(z[0]), 0)
+	s.output[half] = complex(real(z[0])-imag(z[0]), 0)
+
+	for k := 1; k < half; k++ {
+		a, b := z[k], z[half-k]
+		b = complex(real(b), -imag(b))
+
+		even := (a + b) * 0.5
+		odd := (a - b) * complex(0, -0.5)
+
+		s.output[k] = even + (s.unpack[k] * odd)
+	}
+}
+
+// transform writes the fft of the len(out) values of in, every stride
+// values, into out.
+func (s *splitRadix) transform(out, in []complex128, stride int) {
+	var n = len(out)
+
+	switch n {
+	case 1:
+		out[0] = in[0]
+		return
+
+	case 2:
+		a, b := in[0], in[stride]
+		out[0], out[1] = a+b, a-b
+		return
+
+	case 4:
+		x0, x1, x2, x3 := in[0], in[stride], in[2*stride], in[3*stride]
+		t0, t1, t2, t3 := x0+x2, x0-x2, x1+x3, x1-x3
+		// -i * t3
+		t3 = complex(imag(t3), -real(t3))
+		out[0], out[1], out[2], out[3] = t0+t2, t1+t3, t0-t2, t1-t3
+		return
+	}
+
+	var q = n / 4
+
+	s.transform(out[:2*q], in, 2*stride)
+	s.transform(out[2*q:3*q], in[stride:], 4*stride)
+	s.transform(out[3*q:n], in[3*stride:], 4*stride)
+
+	var (
+		tw = s.twiddles[bits.Len(uint(n))][:2*q]
+
+		u0 = out[:q]
+		u1 = out[q : 2*q]
+		z0 = out[2*q : 3*q]
+		z1 = out[3*q : n]
+	)
+
+	for k := range u0 {
+		a := tw[2*k] * z0[k]
+		b := tw[(2*k)+1] * z1[k]
+
+		sum, diff := a+b, a-b
+		// -i * diff
+		diff = complex(imag(diff), -real(diff))
+
+		x0, x1 := u0[k], u1[k]
+
+		u0[k] = x0 + sum
+		z0[k] = x0 - sum
+		u1[k] = x1 + diff
+		z1[k] = x1 - diff
+	}
+}
+
+func (s *splitRadix) close() {}
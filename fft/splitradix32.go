@@ -0,0 +1,144 @@
+package fft
+
+import (
+	"math"
+	"math/bits"
+)
+
+// splitRadix32 is splitRadix in float32. It is less accurate and only pays
+// off where memory bandwidth is the limit. Go multiplies complex64 values in
+// float64, so products are written out in float32.
+type splitRadix32 struct {
+	input  []float64
+	output []complex128
+
+	twiddles [][]complex64 // w^k and w^3k interleaved, by log2 of the size
+	unpack   []complex64   // exp(-2πik/n) for the real size n, k < n/2
+	packed   []complex64   // even samples as real, odd samples as imaginary
+	half     []complex64   // fft of packed
+}
+
+func newSplitRadix32(in []float64, out []complex128) *splitRadix32 {
+	var size = len(in)
+
+	var s = &splitRadix32{
+		input:    in,
+		output:   out,
+		twiddles: make([][]complex64, bits.Len(uint(size))),
+		unpack:   make([]complex64, size/2),
+		packed:   make([]complex64, size/2),
+		half:     make([]complex64, size/2),
+	}
+
+	var twiddle = func(k, n int) complex64 {
+		sin, cos := math.Sincos(-2.0 * math.Pi * float64(k) / float64(n))
+		return complex64(complex(cos, sin))
+	}
+
+	for k := range s.unpack {
+		s.unpack[k] = twiddle(k, size)
+	}
+
+	for n := 8; n <= size/2; n *= 2 {
+		tw := make([]complex64, n/2)
+		for k := 0; k < n/4; k++ {
+			tw[2*k] = twiddle(k, n)
+			tw[(2*k)+1] = twiddle(3*k, n)
+		}
+
+		s.twiddles[bits.Len(uint(n))] = tw
+	}
+
+	return s
+}
+
+func (s *splitRadix32) execute() {
+	var half = len(s.half)
+
+	for idx := range s.packed {
+		s.packed[idx] = complex(float32(s.input[2*idx]), float32(s.input[(2*idx)+1]))
+	}
+
+	s.transform(s.half, s.packed, 1)
+
+	var z = s.half
+
+	s.output[0] = complex(float64(real(z[0])+imag(z[0])), 0)
+	s.output[half] = complex(float64(real(z[0])-imag(z[0])), 0)
+
+	for k := 1; k < half; k++ {
+		a, b := z[k], z[half-k]
+		b = complex(real(b), -imag(b))
+
+		even := (a + b) * 0.5
+		odd := a - b
+		// -i/2 * odd
+		odd = complex(imag(odd)*0.5, real(odd)*-0.5)
+
+		s.output[k] = complex128(even + mul32(s.unpack[k], odd))
+	}
+}
+
+// transform writes the fft of the len(out) values of in, every stride
+// values, into out.
+func (s *splitRadix32) transform(out, in []complex64, stride int) {
+	var n = len(out)
+
+	switch n {
+	case 1:
+		out[0] = in[0]
+		return
+
+	case 2:
+		a, b := in[0], in[stride]
+		out[0], out[1] = a+b, a-b
+		return
+
+	case 4:
+		x0, x1, x2, x3 := in[0], in[stride], in[2*stride], in[3*stride]
+		t0, t1, t2, t3 := x0+x2, x0-x2, x1+x3, x1-x3
+		// -i * t3
+		t3 = complex(imag(t3), -real(t3))
+		out[0], out[1], out[2], out[3] = t0+t2, t1+t3, t0-t2, t1-t3
+		return
+	}
+
+	var q = n / 4
+
+	s.transform(out[:2*q], in, 2*stride)
+	s.transform(out[2*q:3*q], in[stride:], 4*stride)
+	s.transform(out[3*q:n], in[3*stride:], 4*stride)
+
+	var (
+		tw = s.twiddles[bits.Len(uint(n))][:2*q]
+
+		u0 = out[:q]
+		u1 = out[q : 2*q]
+		z0 = out[2*q : 3*q]
+		z1 = out[3*q : n]
+	)
+
+	for k := range u0 {
+		a := mul32(tw[2*k], z0[k])
+		b := mul32(tw[(2*k)+1], z1[k])
+
+		sum, diff := a+b, a-b
+		// -i * diff
+		diff = complex(imag(diff), -real(diff))
+
+		x0, x1 := u0[k], u1[k]
+
+		u0[k] = x0 + sum
+		z0[k] = x0 - sum
+		u1[k] = x1 + diff
+		z1[k] = x1 - diff
+	}
+}
+
+func (s *splitRadix32) close() {}
+
+// mul32 multiplies a and b in float32.
+func mul32(a, b complex64) complex64 {
+	ar, ai, br, bi := real(a), imag(a), real(b), imag(b)
+	return complex((ar*br)-(ai*bi), (ar*bi)+(ai*br))
+}
@@ -6,7 +6,7 @@ require (
 	github.com/integrii/flaggy v1.4.4
 	github.com/lawl/pulseaudio v0.0.0-20200802093727-ab0735955fd0
 	github.com/mattn/go-runewidth v0.0.13 // indirect
-	github.com/nsf/termbox-go v0.0.0-20200418040025-38ba6e5628f1
+	github.com/nsf/termbox-go v1.1.1
 	github.com/pkg/errors v0.9.1
 	gonum.org/v1/gonum v0.8.1
 )
@@ -0,0 +1,112 @@
+package graphic
+
+import (
+	"github.com/nsf/termbox-go"
+)
+
+// BrailleRune is the empty braille pattern. Dots are added to it as bits.
+const BrailleRune = '\u2800'
+
+// brailleBits are the bits of the dots of a braille cell, by column and row.
+var brailleBits = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// GreyPalette is the grey ramp of the 256 color mode, from dark to light.
+var GreyPalette = greyPalette()
+
+func greyPalette() []termbox.Attribute {
+	var palette = make([]termbox.Attribute, 24)
+	for idx := range palette {
+		// attributes are one above the color index.
+		palette[idx] = termbox.Attribute(232 + idx + 1)
+	}
+	return palette
+}
+
+// brailleCanvas is a grid of dots drawn with braille patterns, two dots wide
+// and four dots high per cell. Every dot has an intensity that fades over
+// time, so points persist for a while after they are plotted.
+type brailleCanvas struct {
+	dots []float64
+	cols int // width in cells
+	rows int // height in cells
+}
+
+// resize sets the size of the canvas in cells. It clears the canvas if the
+// size changed.
+func (b *brailleCanvas) resize(cols, rows int) {
+	if cols == b.cols && rows == b.rows {
+		return
+	}
+
+	b.cols, b.rows = cols, rows
+
+	if size := cols * rows * 8; cap(b.dots) < size {
+		b.dots = make([]float64, size)
+	} else {
+		b.dots = b.dots[:size]
+		b.fade(0)
+	}
+}
+
+// width returns the width of the canvas in dots.
+func (b *brailleCanvas) width() int {
+	return b.cols * 2
+}
+
+// height returns the height of the canvas in dots.
+func (b *brailleCanvas) height() int {
+	return b.rows * 4
+}
+
+// fade multiplies the intensity of every dot by factor.
+func (b *brailleCanvas) fade(factor float64) {
+	for idx := range b.dots {
+		b.dots[idx] *= factor
+	}
+}
+
+// plot sets the dot at x, y to full intensity. Dots outside of the canvas
+// are ignored.
+func (b *brailleCanvas) plot(x, y int) {
+	if x < 0 || x >= b.width() || y < 0 || y >= b.height() {
+		return
+	}
+
+	b.dots[(y*b.width())+x] = 1.0
+}
+
+// drawBraille draws the canvas with its top left cell at x, y. Dots dimmer than
+// threshold are not drawn, and cells are colored from palette by their
+// brightest dot.
+func (d *Display) drawBraille(b *brailleCanvas, x, y int, threshold float64, palette []termbox.Attribute) {
+	for row := 0; row < b.rows; row++ {
+		for col := 0; col < b.cols; col++ {
+			var r rune
+			var bright float64
+
+			for dx := 0; dx < 2; dx++ {
+				for dy := 0; dy < 4; dy++ {
+					v := b.dots[((row*4)+dy)*b.width()+(col*2)+dx]
+					if v < threshold {
+						continue
+					}
+
+					r |= brailleBits[dx][dy]
+					if v > bright {
+						bright = v
+					}
+				}
+			}
+
+			if r == 0 {
+				continue
+			}
+
+			level := (bright - threshold) / (1.0 - threshold)
+			d.setCell(x+col, y+row, BrailleRune+r, paletteColor(palette, level), d.styles.Background)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package graphic
+
+import (
+	"github.com/nsf/termbox-go"
+)
+
+// SetBraille sets if bars are drawn with braille dots. Braille bars are
+// sized in dots instead of cells, two dots wide and four dots high per cell,
+// so twice as many bars fit.
+func (d *Display) SetBraille(braille bool) {
+	d.braille = braille
+}
+
+// Braille returns true if bars are drawn with braille dots.
+func (d *Display) Braille() bool {
+	return d.braille
+}
+
+// brailleBars returns true if the current draw type is drawn with braille.
+func (d *Display) brailleBars() bool {
+	if !d.braille {
+		return false
+	}
+
+	switch d.drawType {
+	case DrawUp, DrawDown, DrawUpDown, DrawLeftRight:
+		return true
+	default:
+		return false
+	}
+}
+
+// DrawBraille will draw the bars of the current draw type with braille dots.
+func (d *Display) DrawBraille(bins [][]float64, count int, scale float64) {
+	d.barDots.resize(d.width(), d.height())
+	d.barDots.fade(0)
+
+	setCount := len(bins)
+
+	switch d.drawType {
+	case DrawUp, DrawDown:
+		barSpace := intMax(d.height()-d.baseSize, 0)
+		dots := barSpace * 4
+		scale = float64(dots) / scale
+
+		paddedWidth := (d.binSize * count * setCount) - d.spaceSize
+		paddedWidth = intMax(intMin(paddedWidth, d.barDots.width()), 0)
+
+		channelWidth := d.binSize * count
+		edgeOffset := (d.barDots.width() - paddedWidth) / 2
+
+		base, dir, baseRow := dots-1, -1, barSpace
+		if d.drawType == DrawDown {
+			base, dir, baseRow = d.baseSize*4, 1, 0
+		}
+
+		for xSet, chBins := range bins {
+			for xBar := 0; xBar < count; xBar++ {
+				xBin := (xBar * (1 - xSet)) + (((count - 1) - xBar) * xSet)
+				pos := (xBar * d.binSize) + (channelWidth * xSet) + edgeOffset
+
+				d.plotBar(pos, base, dir, chBins[xBin]*scale, dots, xSet, xBin, scale, true)
+			}
+		}
+
+		d.drawBrailleBase(edgeOffset/2, (edgeOffset+paddedWidth+1)/2, baseRow, baseRow+d.baseSize)
+
+	case DrawUpDown:
+		centerStart := intMax((d.height()-d.baseSize)/2, 0)
+		centerStop := centerStart + d.baseSize
+
+		dots := intMin(centerStart, d.height()-centerStop) * 4
+		scale = float64(dots) / scale
+
+		paddedWidth := (d.binSize * count) - d.spaceSize
+		edgeOffset := intMax((d.barDots.width()-paddedWidth)/2, 0)
+
+		for xBar := 0; xBar < count; xBar++ {
+			pos := (xBar * d.binSize) + edgeOffset
+
+			d.plotBar(pos, (centerStart*4)-1, -1, bins[0][xBar]*scale, dots, 0, xBar, scale, true)
+			d.plotBar(pos, centerStop*4, 1, bins[1%setCount][xBar]*scale, dots, 1%setCount, xBar, scale, true)
+		}
+
+		d.drawBrailleBase(edgeOffset/2, (edgeOffset+paddedWidth+1)/2, centerStart, centerStop)
+
+	case DrawLeftRight:
+		centerStart := intMax((d.width()-d.baseSize)/2, 0)
+		centerStop := centerStart + d.baseSize
+
+		dots := intMin(centerStart, d.width()-centerStop) * 2
+		scale = float64(dots) / scale
+
+		paddedHeight := (d.binSize * count) - d.spaceSize
+		edgeOffset := intMax((d.barDots.height()-paddedHeight)/2, 0)
+
+		for xBar := 0; xBar < count; xBar++ {
+			// draw higher frequencies at the top
+			xBin := count - 1 - xBar
+			pos := (xBar * d.binSize) + edgeOffset
+
+			d.plotBar(pos, (centerStart*2)-1, -1, bins[0][xBin]*scale, dots, 0, xBin, scale, false)
+			d.plotBar(pos, centerStop*2, 1, bins[1%setCount][xBin]*scale, dots, 1%setCount, xBin, scale, false)
+		}
+
+		for xCol := centerStart; xCol < centerStop; xCol++ {
+			for xRow := edgeOffset / 4; xRow < (edgeOffset+paddedHeight+3)/4; xRow++ {
+				d.setCell(xCol, xRow, BarRune, d.styles.CenterLine, d.styles.Background)
+			}
+		}
+	}
+
+	d.drawBraille(&d.barDots, 0, 0, 0.5, []termbox.Attribute{d.styles.Foreground})
+}
+
+// plotBar plots a bar of value dots, up to space dots, starting at the dot
+// base and going in dir, across barSize dots from pos. Bars are vertical or
+// horizontal. The peak of bin xBin in set xSet is plotted above the bar.
+func (d *Display) plotBar(pos, base, dir int, value float64, space, xSet, xBin int, scale float64, vertical bool) {
+	length := intMax(intMin(int(value), space), 0)
+
+	peak, ok := d.peakOffset(xSet, xBin, scale, space)
+	ok = ok && peak >= length
+
+	plot := func(along, across int) {
+		if vertical {
+			d.barDots.plot(across, along)
+		} else {
+			d.barDots.plot(along, across)
+		}
+	}
+
+	for across := pos; across < pos+d.barSize; across++ {
+		for step := 0; step < length; step++ {
+			plot(base+(dir*step), across)
+		}
+
+		if ok {
+			plot(base+(dir*peak), across)
+		}
+	}
+}
+
+// drawBrailleBase draws the base of vertical braille bars over the columns
+// [left, right) of the rows [top, bottom).
+func (d *Display) drawBrailleBase(left, right, top, bottom int) {
+	for xRow := top; xRow < bottom; xRow++ {
+		for xCol := left; xCol < right; xCol++ {
+			d.setCell(xCol, xRow, BarRune, d.styles.CenterLine, d.styles.Background)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package graphic
+
+import (
+	"strings"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Cell is a cell of a buffer.
+type Cell struct {
+	Ch rune
+	Fg termbox.Attribute
+	Bg termbox.Attribute
+}
+
+// Buffer is a renderer that keeps its cells in memory. It draws without a
+// terminal, for tests and outputs that read the cells themselves.
+type Buffer struct {
+	width  int
+	height int
+	back   []Cell
+	front  []Cell
+	events chan Event
+}
+
+// NewBuffer returns a buffer of width columns and height rows.
+func NewBuffer(width, height int) *Buffer {
+	var b = &Buffer{events: make(chan Event, 16)}
+	b.resize(width, height)
+	return b
+}
+
+func (b *Buffer) resize(width, height int) {
+	b.width = intMax(width, 0)
+	b.height = intMax(height, 0)
+	b.back = make([]Cell, b.width*b.height)
+	b.front = make([]Cell, b.width*b.height)
+
+	b.Clear(termbox.ColorDefault, termbox.ColorDefault)
+	copy(b.front, b.back)
+}
+
+// Init does nothing, the buffer is ready when made.
+func (b *Buffer) Init(truecolor bool) error {
+	return nil
+}
+
+// Close does nothing.
+func (b *Buffer) Close() error {
+	return nil
+}
+
+// SetCell sets a cell of the back buffer.
+func (b *Buffer) SetCell(x, y int, r rune, fg, bg termbox.Attribute) {
+	if x < 0 || x >= b.width || y < 0 || y >= b.height {
+		return
+	}
+
+	b.back[(y*b.width)+x] = Cell{Ch: r, Fg: fg, Bg: bg}
+}
+
+// Size returns the size of the buffer.
+func (b *Buffer) Size() (int, int) {
+	return b.width, b.height
+}
+
+// Clear clears the back buffer.
+func (b *Buffer) Clear(fg, bg termbox.Attribute) error {
+	for idx := range b.back {
+		b.back[idx] = Cell{Ch: SpaceRune, Fg: fg, Bg: bg}
+	}
+
+	return nil
+}
+
+// Flush copies the back buffer to the front buffer.
+func (b *Buffer) Flush() error {
+	copy(b.front, b.back)
+	return nil
+}
+
+// PollEvent waits for the next event sent to the buffer.
+func (b *Buffer) PollEvent() Event {
+	return <-b.events
+}
+
+// Interrupt makes PollEvent return an EventInterrupt.
+func (b *Buffer) Interrupt() {
+	b.Send(Event{Type: EventInterrupt})
+}
+
+// Send queues an event for PollEvent, it is dropped if the queue is full.
+func (b *Buffer) Send(ev Event) {
+	select {
+	case b.events <- ev:
+	default:
+	}
+}
+
+// Resize changes the size of the buffer and sends an EventResize.
+func (b *Buffer) Resize(width, height int) {
+	b.resize(width, height)
+	b.Send(Event{Type: EventResize, Width: b.width, Height: b.height})
+}
+
+// Cells returns the rows of cells as they were last flushed.
+func (b *Buffer) Cells() [][]Cell {
+	var rows = make([][]Cell, b.height)
+	for y := range rows {
+		rows[y] = b.front[y*b.width : (y+1)*b.width]
+	}
+
+	return rows
+}
+
+// String returns the runes of the cells as they were last flushed, a line
+// per row.
+func (b *Buffer) String() string {
+	var sb strings.Builder
+
+	for _, row := range b.Cells() {
+		for _, cell := range row {
+			sb.WriteRune(cell.Ch)
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,277 @@
+package graphic
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nsf/termbox-go"
+	"github.com/pkg/errors"
+)
+
+// Color is a 24-bit color.
+type Color struct {
+	R uint8
+	G uint8
+	B uint8
+}
+
+// ParseColor parses a hex color as #rrggbb or #rgb, or a color of the 256
+// color mode as "0" to "255".
+func ParseColor(s string) (Color, error) {
+	s = strings.TrimSpace(s)
+
+	if !strings.HasPrefix(s, "#") {
+		idx, err := strconv.Atoi(s)
+		if err != nil || idx < 0 || idx > 255 {
+			return Color{}, errors.Errorf("invalid color %q", s)
+		}
+
+		return xtermPalette[idx], nil
+	}
+
+	var digits = s[1:]
+
+	if len(digits) == 3 {
+		digits = string([]byte{
+			digits[0], digits[0], digits[1], digits[1], digits[2], digits[2],
+		})
+	}
+
+	if len(digits) != 6 {
+		return Color{}, errors.Errorf("invalid color %q", s)
+	}
+
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return Color{}, errors.Errorf("invalid color %q", s)
+	}
+
+	return Color{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value)}, nil
+}
+
+// String returns the color as #rrggbb.
+func (c Color) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// xtermPalette is the rgb value of each color in the 256 color mode.
+var xtermPalette = xtermColors()
+
+func xtermColors() [256]Color {
+	var palette = [256]Color{
+		{0, 0, 0}, {128, 0, 0}, {0, 128, 0}, {128, 128, 0},
+		{0, 0, 128}, {128, 0, 128}, {0, 128, 128}, {192, 192, 192},
+		{128, 128, 128}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+		{0, 0, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+	}
+
+	var levels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+	for idx := 0; idx < 216; idx++ {
+		palette[16+idx] = Color{levels[idx/36], levels[(idx/6)%6], levels[idx%6]}
+	}
+
+	for idx := 0; idx < 24; idx++ {
+		var grey = uint8(8 + (idx * 10))
+		palette[232+idx] = Color{grey, grey, grey}
+	}
+
+	return palette
+}
+
+// Nearest returns the index of the nearest color of the 256 color mode. The
+// first 16 colors are skipped as terminals are free to change them.
+func (c Color) Nearest() int {
+	var best, bestDist = 16, -1
+
+	for idx := 16; idx < len(xtermPalette); idx++ {
+		var p = xtermPalette[idx]
+		var dr = int(c.R) - int(p.R)
+		var dg = int(c.G) - int(p.G)
+		var db = int(c.B) - int(p.B)
+
+		if dist := (dr * dr) + (dg * dg) + (db * db); bestDist < 0 || dist < bestDist {
+			best, bestDist = idx, dist
+		}
+	}
+
+	return best
+}
+
+// Gradient is a set of evenly spaced color stops.
+type Gradient []Color
+
+// DefaultGradient runs from green through yellow to red.
+var DefaultGradient = Gradient{{0, 255, 0}, {255, 255, 0}, {255, 0, 0}}
+
+// ParseGradient parses a list of color stops, as ParseColor does.
+func ParseGradient(stops []string) (Gradient, error) {
+	var gradient = make(Gradient, len(stops))
+
+	for idx, stop := range stops {
+		color, err := ParseColor(stop)
+		if err != nil {
+			return nil, err
+		}
+
+		gradient[idx] = color
+	}
+
+	return gradient, nil
+}
+
+// At returns the color at t in [0, 1] along the gradient.
+func (g Gradient) At(t float64) Color {
+	switch {
+	case len(g) == 0:
+		return Color{}
+	case len(g) == 1 || t <= 0 || t != t:
+		return g[0]
+	case t >= 1:
+		return g[len(g)-1]
+	}
+
+	var pos = t * float64(len(g)-1)
+	var idx = int(pos)
+	var frac = pos - float64(idx)
+
+	var mix = func(a, b uint8) uint8 {
+		return uint8(float64(a) + ((float64(b) - float64(a)) * frac) + 0.5)
+	}
+
+	var a, b = g[idx], g[idx+1]
+
+	return Color{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B)}
+}
+
+// GradientMode is what the gradient runs across.
+type GradientMode int
+
+// gradient modes
+const (
+	// GradientNone draws bars in the foreground color.
+	GradientNone GradientMode = iota
+	// GradientHeight colors bars by height.
+	GradientHeight
+	// GradientFrequency colors bars by frequency.
+	GradientFrequency
+	// GradientChannel colors bars by channel.
+	GradientChannel
+	GradientMax
+)
+
+// SetGradient sets the gradient and what it runs across.
+func (d *Display) SetGradient(mode GradientMode, gradient Gradient) {
+	if len(gradient) == 0 {
+		gradient = DefaultGradient
+	}
+
+	d.gradientMode = mode
+	d.gradient = gradient
+	d.flagGradient = gradient
+	d.barColors = d.barColors[:0]
+
+	d.updateStyleBuffer()
+}
+
+// SetTrueColor sets if 24-bit colors are drawn. Without truecolor, colors
+// fall back to the nearest color of the 256 color mode.
+func (d *Display) SetTrueColor(truecolor bool) {
+	d.truecolor = truecolor
+	d.barColors = d.barColors[:0]
+}
+
+// TrueColor returns true if the terminal says it supports 24-bit colors.
+func TrueColor() bool {
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return true
+	default:
+		return false
+	}
+}
+
+// color returns the attribute for c.
+func (d *Display) color(c Color) termbox.Attribute {
+	if d.truecolor {
+		return termbox.RGBToAttribute(c.R, c.G, c.B)
+	}
+
+	return termbox.Attribute(c.Nearest() + 1)
+}
+
+// barColor returns the color of the bar of bin xBin out of count in set xSet
+// when the gradient runs across bars, or the foreground otherwise.
+func (d *Display) barColor(xSet, xBin, count int) termbox.Attribute {
+	switch d.gradientMode {
+	case GradientFrequency:
+		if len(d.barColors) != count {
+			d.barColors = d.barColors[:0]
+			for idx := 0; idx < count; idx++ {
+				t := float64(idx) / float64(intMax(count-1, 1))
+				d.barColors = append(d.barColors, d.color(d.gradient.At(t)))
+			}
+		}
+
+		return d.barColors[xBin]
+
+	case GradientChannel:
+		return d.color(d.gradient.At(float64(xSet)))
+
+	default:
+		return d.styles.Foreground
+	}
+}
+
+// barStyle returns the style of a bar cell at idx of the style buffer. The
+// bar color replaces the foreground but not the center line.
+func (d *Display) barStyle(idx int, bar termbox.Attribute) termbox.Attribute {
+	if idx < 0 || idx >= len(d.styleBuffer) {
+		return bar
+	}
+
+	if style := d.styleBuffer[idx]; style != d.styles.Foreground {
+		return style
+	}
+
+	return bar
+}
+
+// peakStyle returns the style of a peak cap on a bar of style bar.
+func (d *Display) peakStyle(bar termbox.Attribute) termbox.Attribute {
+	if d.styles.Peak != termbox.ColorDefault {
+		return d.styles.Peak
+	}
+
+	return bar
+}
+
+// rgbAttribute converts an attribute of the 256 color mode to a 24-bit one.
+// The default color cannot carry attributes in 24-bit mode, so they are
+// dropped from it.
+func rgbAttribute(a termbox.Attribute) termbox.Attribute {
+	if a >= termbox.RGBToAttribute(0, 0, 0) {
+		return a
+	}
+
+	var idx = int(a & 0x1FF)
+	if idx == 0 || idx > len(xtermPalette) {
+		return termbox.ColorDefault
+	}
+
+	var c = xtermPalette[idx-1]
+
+	return termbox.RGBToAttribute(c.R, c.G, c.B) | (a &^ 0x1FF)
+}
+
+// trueColorCell converts the colors of a cell for the 24-bit mode. Reverse
+// is applied here by swapping the colors.
+func trueColorCell(fg, bg termbox.Attribute) (termbox.Attribute, termbox.Attribute) {
+	if (fg|bg)&StyleReverse != 0 {
+		fg, bg = bg&^StyleReverse, fg&^StyleReverse
+	}
+
+	return rgbAttribute(fg), rgbAttribute(bg)
+}
@@ -2,6 +2,8 @@ package graphic
 
 import (
 	"context"
+	"image"
+	"math"
 	"sync/atomic"
 
 	"github.com/nsf/termbox-go"
@@ -15,9 +17,13 @@ const (
 	SpaceRune = '\u0020'
 
 	BarRuneV = '\u2580'
+	BarRuneL = '\u2584'
 	BarRune  = '\u2588'
 	BarRuneH = '\u2590'
 
+	// ShadeRunes are the runes used for intensity, from empty to full.
+	ShadeRunes = " \u2591\u2592\u2593\u2588"
+
 	StyleReverse = termbox.AttrReverse
 
 	// NumRunes number of runes for sub step bars
@@ -34,6 +40,13 @@ const (
 	DrawUpDown
 	DrawDown
 	DrawLeftRight
+	DrawChroma
+	DrawChromaStrip
+	DrawSpectrogram
+	DrawScope
+	DrawScopeOverlay
+	DrawVectorscope
+	DrawLissajous
 	DrawMax
 
 	// DrawDefault is the default draw type.
@@ -45,6 +58,10 @@ type Styles struct {
 	Foreground termbox.Attribute
 	Background termbox.Attribute
 	CenterLine termbox.Attribute
+	// Peak is the color of peak caps, the default color follows the bars.
+	Peak termbox.Attribute
+	// Text is the color of text, the default color follows the foreground.
+	Text termbox.Attribute
 }
 
 // DefaultStyles returns the default styles.
@@ -75,16 +92,52 @@ func (s Styles) AsUInt16s() (fg, bg, center uint16) {
 
 // Display handles drawing our visualizer.
 type Display struct {
-	running     uint32
-	barSize     int
-	spaceSize   int
-	binSize     int
-	baseSize    int
-	termWidth   int
-	termHeight  int
-	drawType    DrawType
-	styles      Styles
-	styleBuffer []termbox.Attribute
+	running      uint32
+	renderer     Renderer
+	barSize      int
+	spaceSize    int
+	binSize      int
+	baseSize     int
+	termWidth    int
+	termHeight   int
+	drawType     DrawType
+	styles       Styles
+	styleBuffer  []termbox.Attribute
+	labels       []string
+	history      history
+	merged       []float64
+	samples      [][]float64
+	vector       brailleCanvas
+	braille      bool
+	barDots      brailleCanvas
+	fill         bool
+	cover        []int
+	truecolor    bool
+	gradient     Gradient
+	flagGradient Gradient
+	gradientMode GradientMode
+	themes       []Theme
+	theme        int
+	barColors    []termbox.Attribute
+	pixels       *image.NRGBA
+	pixelShown   bool
+	peaks        [][]float64
+	meters       []Meter
+	meterText    []string
+	meterWidth   int
+	stereo       *Stereo
+	headerHeight int
+	keyFunc      KeyFunc
+}
+
+// KeyFunc is called with every character key, and ' ' for space, before the
+// display handles it.
+// It returns true if it handled the key, in which case the display does not.
+type KeyFunc func(ch rune) bool
+
+// SetKeyFunc sets the function called for character keys.
+func (d *Display) SetKeyFunc(fn KeyFunc) {
+	d.keyFunc = fn
 }
 
 // Init initializes the display.
@@ -93,23 +146,22 @@ func (d *Display) Init() error {
 	// make a large buffer as this could be as big as the screen width/height.
 	d.styleBuffer = make([]termbox.Attribute, 4096)
 
-	if err := termbox.Init(); err != nil {
-		return err
+	if d.renderer == nil {
+		d.renderer = Termbox{}
 	}
 
-	termbox.SetInputMode(termbox.InputAlt)
-	termbox.SetOutputMode(termbox.Output256)
-	termbox.HideCursor()
+	if err := d.renderer.Init(d.truecolor); err != nil {
+		return err
+	}
 
-	d.termWidth, d.termHeight = termbox.Size()
+	d.termWidth, d.termHeight = d.renderer.Size()
 
 	return nil
 }
 
 // Close will stop display and clean up the terminal.
 func (d *Display) Close() error {
-	termbox.Close()
-	return nil
+	return d.renderer.Close()
 }
 
 // Start display is bad.
@@ -125,10 +177,14 @@ func (d *Display) Start(ctx context.Context) context.Context {
 
 		for {
 
-			var ev = termbox.PollEvent()
+			var ev = d.renderer.PollEvent()
 
 			switch ev.Type {
-			case termbox.EventKey:
+			case EventKey:
+				if ev.Ch != 0 && d.keyFunc != nil && d.keyFunc(ev.Ch) {
+					break
+				}
+
 				switch ev.Key {
 
 				case termbox.KeyArrowUp:
@@ -144,7 +200,10 @@ func (d *Display) Start(ctx context.Context) context.Context {
 					d.AdjustSizes(0, -1)
 
 				case termbox.KeySpace:
-					d.SetDrawType(d.drawType + 1)
+					// the key function may switch between draws instead.
+					if d.keyFunc == nil || !d.keyFunc(' ') {
+						d.SetDrawType(d.drawType + 1)
+					}
 
 				case termbox.KeyCtrlC:
 					return
@@ -166,12 +225,12 @@ func (d *Display) Start(ctx context.Context) context.Context {
 
 				} // switch ev.Key
 
-			case termbox.EventResize:
+			case EventResize:
 				d.termWidth = ev.Width
 				d.termHeight = ev.Height
 				d.updateStyleBuffer()
 
-			case termbox.EventInterrupt:
+			case EventInterrupt:
 				return
 
 			default:
@@ -192,6 +251,16 @@ func (d *Display) Start(ctx context.Context) context.Context {
 	return dispCtx
 }
 
+// width returns the number of columns available to the bars.
+func (d *Display) width() int {
+	return intMax(d.termWidth-d.meterWidth, 0)
+}
+
+// height returns the number of rows below the header.
+func (d *Display) height() int {
+	return intMax(d.termHeight-d.headerHeight, 0)
+}
+
 func intMax(x1, x2 int) int {
 	if x1 < x2 {
 		return x2
@@ -209,42 +278,61 @@ func intMin(x1, x2 int) int {
 func (d *Display) updateStyleBuffer() {
 	switch d.drawType {
 	case DrawUp:
-		d.fillStyleBuffer(d.termHeight-d.baseSize, d.baseSize, 0)
+		d.fillStyleBuffer(d.height()-d.baseSize, d.baseSize, 0, false)
 
 	case DrawUpDown:
-		centerStart := intMax((d.termHeight-d.baseSize)/2, 0)
+		centerStart := intMax((d.height()-d.baseSize)/2, 0)
 		centerStop := centerStart + d.baseSize
-		d.fillStyleBuffer(centerStart, d.baseSize, d.termHeight-centerStop)
+		d.fillStyleBuffer(centerStart, d.baseSize, d.height()-centerStop, true)
 
 	case DrawDown:
-		d.fillStyleBuffer(0, d.baseSize, d.termHeight-d.baseSize)
+		d.fillStyleBuffer(0, d.baseSize, d.height()-d.baseSize, false)
 
 	case DrawLeftRight:
-		centerStart := intMax((d.termWidth-d.baseSize)/2, 0)
+		centerStart := intMax((d.width()-d.baseSize)/2, 0)
 		centerStop := centerStart + d.baseSize
-		d.fillStyleBuffer(centerStart, d.baseSize, d.termWidth-centerStop)
+		d.fillStyleBuffer(centerStart, d.baseSize, d.width()-centerStop, true)
 	}
 }
 
-func (d *Display) fillStyleBuffer(left, center, right int) {
+// fillStyleBuffer fills the style buffer with the bars to the left of the
+// center, the center and the bars to the right of it. Split is true if the
+// sides are different channels.
+func (d *Display) fillStyleBuffer(left, center, right int, split bool) {
 	i := 0
 	for stop := left; i < stop; i++ {
-		d.styleBuffer[i] = d.styles.Foreground
+		d.styleBuffer[i] = d.sideStyle(stop-1-i, left, 0, split)
 	}
 
 	for stop := i + center; i < stop; i++ {
 		d.styleBuffer[i] = d.styles.CenterLine
 	}
 
-	for stop := i + right; i < stop; i++ {
-		d.styleBuffer[i] = d.styles.Foreground
+	for start, stop := i, i+right; i < stop; i++ {
+		d.styleBuffer[i] = d.sideStyle(i-start, right, 1, split)
+	}
+}
+
+// sideStyle returns the style of the cell dist away from the center on the
+// side of set xSet with size cells.
+func (d *Display) sideStyle(dist, size, xSet int, split bool) termbox.Attribute {
+	switch {
+	case d.gradientMode == GradientHeight:
+		t := float64(dist) / float64(intMax(size-1, 1))
+		return d.color(d.gradient.At(t))
+
+	case d.gradientMode == GradientChannel && split:
+		return d.color(d.gradient.At(float64(xSet)))
+
+	default:
+		return d.styles.Foreground
 	}
 }
 
 // Stop display not work.
 func (d *Display) Stop() error {
 	if atomic.CompareAndSwapUint32(&d.running, 1, 0) {
-		termbox.Interrupt()
+		d.renderer.Interrupt()
 	}
 
 	return nil
@@ -253,24 +341,63 @@ func (d *Display) Stop() error {
 // Draw takes data and draws.
 func (d *Display) Draw(bufs [][]float64, channels, count int, scale float64) error {
 
-	switch d.drawType {
-	case DrawUp:
+	if d.fill {
+		d.resetCover()
+	}
+
+	canvas, pixels := d.pixelCanvas()
+
+	switch {
+	case pixels:
+		d.DrawPixels(canvas, bufs, count, scale)
+	case d.brailleBars():
+		d.DrawBraille(bufs, count, scale)
+	case d.drawType == DrawUp:
 		d.DrawUp(bufs, count, scale)
-	case DrawUpDown:
+	case d.drawType == DrawUpDown:
 		d.DrawUpDown(bufs, count, scale)
-	case DrawDown:
+	case d.drawType == DrawDown:
 		d.DrawDown(bufs, count, scale)
-	case DrawLeftRight:
+	case d.drawType == DrawLeftRight:
 		d.DrawLeftRight(bufs, count, scale)
+	case d.drawType == DrawChroma:
+		d.DrawChroma(bufs, count, scale)
+	case d.drawType == DrawChromaStrip:
+		d.DrawChromaStrip(bufs, count, scale)
+	case d.drawType == DrawSpectrogram:
+		d.DrawSpectrogram(bufs, count, scale)
+	case d.drawType == DrawScope:
+		d.DrawScope(false)
+	case d.drawType == DrawScopeOverlay:
+		d.DrawScope(true)
+	case d.drawType == DrawVectorscope:
+		d.DrawVector(true)
+	case d.drawType == DrawLissajous:
+		d.DrawVector(false)
 	default:
 		return nil
 	}
 
-	termbox.Flush()
+	if !pixels && d.pixelShown {
+		d.pixelShown = false
+		if canvas, ok := d.renderer.(ImageCanvas); ok {
+			canvas.SetImage(0, 0, nil)
+		}
+	}
 
-	termbox.Clear(d.styles.Foreground, d.styles.Background)
+	d.drawMeters()
+	d.drawStereo(len(bufs), pixels)
 
-	return nil
+	if err := d.renderer.Flush(); err != nil {
+		return err
+	}
+
+	fg, bg := d.styles.Foreground, d.styles.Background
+	if d.truecolor {
+		fg, bg = trueColorCell(fg, bg)
+	}
+
+	return d.renderer.Clear(fg, bg)
 }
 
 // SetSizes takes a bar size and spacing size.
@@ -319,9 +446,40 @@ func (d *Display) SetDrawType(dt DrawType) {
 		d.drawType = dt
 	}
 
+	// the history of one draw type means nothing to the next.
+	d.history.resize(0, 0)
+
 	d.updateStyleBuffer()
 }
 
+// SetPeaks sets the peaks drawn above the bars of the next draws. Peaks are
+// in the same units as the bars. Peaks are not drawn if peaks is nil.
+func (d *Display) SetPeaks(peaks [][]float64) {
+	d.peaks = peaks
+}
+
+// peakOffset returns how many cells away from the base of its bar the peak
+// of bin xBin in set xSet is, limited to space. ok is false if there are no
+// peaks to draw.
+func (d *Display) peakOffset(xSet, xBin int, scale float64, space int) (int, bool) {
+	if xSet >= len(d.peaks) || space <= 0 {
+		return 0, false
+	}
+
+	return intMax(intMin(int(d.peaks[xSet][xBin]*scale), space-1), 0), true
+}
+
+// DrawType returns the current draw type.
+func (d *Display) DrawType() DrawType {
+	return d.drawType
+}
+
+// SetLabels sets the labels drawn along side the bars of draw types that have
+// a fixed number of bars, such as DrawChroma.
+func (d *Display) SetLabels(labels []string) {
+	d.labels = labels
+}
+
 // Bars returns the number of bars we will draw.
 func (d *Display) Bars(sets ...int) int {
 	var x = 1
@@ -329,19 +487,42 @@ func (d *Display) Bars(sets ...int) int {
 		x = sets[0]
 	}
 
+	// braille bars are sized in dots.
+	var width, height = d.width(), d.height()
+	if d.brailleBars() {
+		width, height = width*2, height*4
+	}
+
 	switch d.drawType {
 	case DrawUp, DrawDown:
-		return (d.termWidth / d.binSize) / x
+		return (width / d.binSize) / x
 	case DrawUpDown:
-		return d.termWidth / d.binSize
+		return width / d.binSize
 	case DrawLeftRight:
-		return d.termHeight / d.binSize
+		return height / d.binSize
+	case DrawSpectrogram:
+		return d.height() * 2
 	default:
 		return 0
 	}
 }
 
+// sizeAndCap returns where a bar of value rows in space rows ends, and the
+// rune capping the eighths past its last whole row. Bars with a zeroBase grow
+// up from the end of the space and the size is the row they start at.
+//
+// NaN and negative values are clamped to 0 and draw nothing, and values past
+// the space, +Inf included, are clamped to fill it. They used to be turned
+// into steps unchecked, where NaN and infinities have no defined int and
+// negative values drew past the space.
 func sizeAndCap(value float64, space int, zeroBase bool, baseRune rune) (int, rune) {
+	switch {
+	case !(value > 0):
+		value = 0
+	case value > float64(space):
+		value = float64(space)
+	}
+
 	var steps, stop = int(value * NumRunes), space * NumRunes
 
 	if zeroBase {
@@ -364,14 +545,14 @@ func sizeAndCap(value float64, space int, zeroBase bool, baseRune rune) (int, ru
 // DrawUp will draw up.
 func (d *Display) DrawUp(bins [][]float64, count int, scale float64) {
 
-	barSpace := intMax(d.termHeight-d.baseSize, 0)
+	barSpace := intMax(d.height()-d.baseSize, 0)
 	scale = float64(barSpace) / scale
 
 	paddedWidth := (d.binSize * count * len(bins)) - d.spaceSize
-	paddedWidth = intMax(intMin(paddedWidth, d.termWidth), 0)
+	paddedWidth = intMax(intMin(paddedWidth, d.width()), 0)
 
 	channelWidth := d.binSize * count
-	edgeOffset := (d.termWidth - paddedWidth) / 2
+	edgeOffset := (d.width() - paddedWidth) / 2
 
 	for xSet, chBins := range bins {
 
@@ -380,17 +561,32 @@ func (d *Display) DrawUp(bins [][]float64, count int, scale float64) {
 			xBin := (xBar * (1 - xSet)) + (((count - 1) - xBar) * xSet)
 			start, bCap := sizeAndCap(chBins[xBin]*scale, barSpace, true, BarRuneV)
 
+			pRow, peak := d.peakOffset(xSet, xBin, scale, barSpace)
+			pRow = barSpace - 1 - pRow
+			peak = peak && pRow < start-1
+
+			bar := d.barColor(xSet, xBin, count)
+
 			xCol := (xBar * d.binSize) + (channelWidth * xSet) + edgeOffset
-			lCol := xCol + d.barSize
+			span := d.barSpan(xBar+(count*xSet), count*len(bins), xCol, xCol+d.barSize)
+
+			for xCol := span.first(); xCol < span.last(); xCol++ {
 
-			for ; xCol < lCol; xCol++ {
+				part, ok := d.cellPart(span, xCol)
+				if !ok {
+					continue
+				}
+
+				if peak && part.half() {
+					d.setCell(xCol, pRow, PeakRune, d.peakStyle(d.barStyle(pRow, bar)), d.styles.Background)
+				}
 
-				if bCap > BarRuneV {
-					termbox.SetCell(xCol, start-1, bCap, d.styles.Foreground, d.styles.Background)
+				if bCap > BarRuneV && part.half() {
+					d.setCell(xCol, start-1, bCap, d.barStyle(start-1, bar), d.styles.Background)
 				}
 
-				for xRow := start; xRow < d.termHeight; xRow++ {
-					termbox.SetCell(xCol, xRow, BarRune, d.styleBuffer[xRow], d.styles.Background)
+				for xRow := start; xRow < d.height(); xRow++ {
+					d.setBarCell(xCol, xRow, part, d.barStyle(xRow, bar), d.styles.Background)
 				}
 			}
 		}
@@ -400,14 +596,14 @@ func (d *Display) DrawUp(bins [][]float64, count int, scale float64) {
 // DrawDown will draw down.
 func (d *Display) DrawDown(bins [][]float64, count int, scale float64) {
 
-	barSpace := intMax(d.termHeight-d.baseSize, 0)
+	barSpace := intMax(d.height()-d.baseSize, 0)
 	scale = float64(barSpace) / scale
 
 	paddedWidth := (d.binSize * count * len(bins)) - d.spaceSize
-	paddedWidth = intMax(intMin(paddedWidth, d.termWidth), 0)
+	paddedWidth = intMax(intMin(paddedWidth, d.width()), 0)
 
 	channelWidth := d.binSize * count
-	edgeOffset := (d.termWidth - paddedWidth) / 2
+	edgeOffset := (d.width() - paddedWidth) / 2
 
 	for xSet, chBins := range bins {
 
@@ -415,22 +611,37 @@ func (d *Display) DrawDown(bins [][]float64, count int, scale float64) {
 
 			xBin := (xBar * (1 - xSet)) + (((count - 1) - xBar) * xSet)
 			stop, bCap := sizeAndCap(chBins[xBin]*scale, barSpace, false, BarRune)
-			if stop += d.baseSize; stop >= d.termHeight {
-				stop = d.termHeight
+			if stop += d.baseSize; stop >= d.height() {
+				stop = d.height()
 				bCap = BarRune
 			}
 
+			pRow, peak := d.peakOffset(xSet, xBin, scale, barSpace)
+			pRow += d.baseSize
+			peak = peak && pRow > stop
+
+			bar := d.barColor(xSet, xBin, count)
+
 			xCol := (xBar * d.binSize) + (channelWidth * xSet) + edgeOffset
-			lCol := xCol + d.barSize
+			span := d.barSpan(xBar+(count*xSet), count*len(bins), xCol, xCol+d.barSize)
+
+			for xCol := span.first(); xCol < span.last(); xCol++ {
+
+				part, ok := d.cellPart(span, xCol)
+				if !ok {
+					continue
+				}
 
-			for ; xCol < lCol; xCol++ {
+				if peak && part.half() {
+					d.setCell(xCol, pRow, PeakRune, d.peakStyle(d.barStyle(pRow, bar)), d.styles.Background)
+				}
 
 				for xRow := 0; xRow < stop; xRow++ {
-					termbox.SetCell(xCol, xRow, BarRune, d.styleBuffer[xRow], d.styles.Background)
+					d.setBarCell(xCol, xRow, part, d.barStyle(xRow, bar), d.styles.Background)
 				}
 
-				if bCap < BarRune {
-					termbox.SetCell(xCol, stop, bCap, StyleReverse, d.styles.Foreground)
+				if bCap < BarRune && part.half() {
+					d.setCell(xCol, stop, bCap, StyleReverse, d.barStyle(stop, bar))
 				}
 			}
 		}
@@ -440,12 +651,12 @@ func (d *Display) DrawDown(bins [][]float64, count int, scale float64) {
 // DrawUpDown will draw up and down.
 func (d *Display) DrawUpDown(bins [][]float64, count int, scale float64) {
 
-	centerStart := intMax((d.termHeight-d.baseSize)/2, 0)
+	centerStart := intMax((d.height()-d.baseSize)/2, 0)
 	centerStop := centerStart + d.baseSize
 
-	scale = float64(intMin(centerStart, d.termHeight-centerStop)) / scale
+	scale = float64(intMin(centerStart, d.height()-centerStop)) / scale
 
-	edgeOffset := intMax((d.termWidth-((d.binSize*count)-d.spaceSize))/2, 0)
+	edgeOffset := intMax((d.width()-((d.binSize*count)-d.spaceSize))/2, 0)
 
 	setCount := len(bins)
 
@@ -453,27 +664,55 @@ func (d *Display) DrawUpDown(bins [][]float64, count int, scale float64) {
 
 		lStart, lCap := sizeAndCap(bins[0][xBar]*scale, centerStart, true, BarRuneV)
 		rStop, rCap := sizeAndCap(bins[1%setCount][xBar]*scale, centerStart, false, BarRune)
-		if rStop += centerStop; rStop >= d.termHeight {
-			rStop = d.termHeight
+		if rStop += centerStop; rStop >= d.height() {
+			rStop = d.height()
 			rCap = BarRune
 		}
 
+		lPeakRow, lPeak := d.peakOffset(0, xBar, scale, centerStart)
+		lPeakRow = centerStart - 1 - lPeakRow
+		lPeak = lPeak && lPeakRow < lStart-1
+
+		rPeakRow, rPeak := d.peakOffset(1%setCount, xBar, scale, centerStart)
+		rPeakRow += centerStop
+		rPeak = rPeak && rPeakRow > rStop
+
+		lBar := d.barColor(0, xBar, count)
+		rBar := d.barColor(1%setCount, xBar, count)
+
 		xCol := xBar*d.binSize + edgeOffset
-		lCol := intMin(xCol+d.barSize, d.termWidth)
+		span := d.barSpan(xBar, count, xCol, intMin(xCol+d.barSize, d.width()))
 
-		for ; xCol < lCol; xCol++ {
+		for xCol := span.first(); xCol < span.last(); xCol++ {
+
+			part, ok := d.cellPart(span, xCol)
+			if !ok {
+				continue
+			}
 
-			if lCap > BarRuneV {
-				termbox.SetCell(xCol, lStart-1, lCap, d.styles.Foreground, d.styles.Background)
+			if lPeak && part.half() {
+				d.setCell(xCol, lPeakRow, PeakRune, d.peakStyle(d.barStyle(lPeakRow, lBar)), d.styles.Background)
 			}
 
-			for xRow := lStart; xRow < rStop; xRow++ {
-				termbox.SetCell(xCol, xRow, BarRune, d.styleBuffer[xRow], d.styles.Background)
+			if rPeak && part.half() {
+				d.setCell(xCol, rPeakRow, PeakRune, d.peakStyle(d.barStyle(rPeakRow, rBar)), d.styles.Background)
+			}
+
+			if lCap > BarRuneV && part.half() {
+				d.setCell(xCol, lStart-1, lCap, d.barStyle(lStart-1, lBar), d.styles.Background)
+			}
+
+			for xRow := lStart; xRow < centerStart; xRow++ {
+				d.setBarCell(xCol, xRow, part, d.barStyle(xRow, lBar), d.styles.Background)
+			}
+
+			for xRow := intMax(lStart, centerStart); xRow < rStop; xRow++ {
+				d.setBarCell(xCol, xRow, part, d.barStyle(xRow, rBar), d.styles.Background)
 			}
 
 			// last part of right bars.
-			if rCap < BarRune {
-				termbox.SetCell(xCol, rStop, rCap, StyleReverse, d.styles.Foreground)
+			if rCap < BarRune && part.half() {
+				d.setCell(xCol, rStop, rCap, StyleReverse, d.barStyle(rStop, rBar))
 			}
 		}
 	}
@@ -481,12 +720,12 @@ func (d *Display) DrawUpDown(bins [][]float64, count int, scale float64) {
 
 // DrawLeftRight will draw left and right.
 func (d *Display) DrawLeftRight(bins [][]float64, count int, scale float64) {
-	centerStart := intMax((d.termWidth-d.baseSize)/2, 0)
+	centerStart := intMax((d.width()-d.baseSize)/2, 0)
 	centerStop := centerStart + d.baseSize
 
-	scale = float64(intMin(centerStart, d.termWidth-centerStop)) / scale
+	scale = float64(intMin(centerStart, d.width()-centerStop)) / scale
 
-	edgeOffset := intMax((d.termHeight-((d.binSize*count)-d.spaceSize))/2, 0)
+	edgeOffset := intMax((d.height()-((d.binSize*count)-d.spaceSize))/2, 0)
 
 	setCount := len(bins)
 
@@ -497,27 +736,179 @@ func (d *Display) DrawLeftRight(bins [][]float64, count int, scale float64) {
 
 		lStart, lCap := sizeAndCap(bins[0][xBin]*scale, centerStart, true, BarRune)
 		rStop, rCap := sizeAndCap(bins[1%setCount][xBin]*scale, centerStart, false, BarRuneH)
-		if rStop += centerStop; rStop >= d.termWidth {
-			rStop = d.termWidth
+		if rStop += centerStop; rStop >= d.width() {
+			rStop = d.width()
 			rCap = BarRuneH
 		}
 
+		lPeakCol, lPeak := d.peakOffset(0, xBin, scale, centerStart)
+		lPeakCol = centerStart - 1 - lPeakCol
+		lPeak = lPeak && lPeakCol < lStart-1
+
+		rPeakCol, rPeak := d.peakOffset(1%setCount, xBin, scale, centerStart)
+		rPeakCol += centerStop
+		rPeak = rPeak && rPeakCol > rStop
+
+		lBar := d.barColor(0, xBin, count)
+		rBar := d.barColor(1%setCount, xBin, count)
+
 		xRow := xBar*d.binSize + edgeOffset
-		lRow := intMin(xRow+d.barSize, d.termHeight)
+		lRow := intMin(xRow+d.barSize, d.height())
 
 		for ; xRow < lRow; xRow++ {
 
+			if lPeak {
+				d.setCell(lPeakCol, xRow, PeakRuneH, d.peakStyle(d.barStyle(lPeakCol, lBar)), d.styles.Background)
+			}
+
+			if rPeak {
+				d.setCell(rPeakCol, xRow, PeakRuneH, d.peakStyle(d.barStyle(rPeakCol, rBar)), d.styles.Background)
+			}
+
 			if lCap > BarRune {
-				termbox.SetCell(lStart-1, xRow, lCap, StyleReverse, d.styles.Background)
+				d.setCell(lStart-1, xRow, lCap, d.barStyle(lStart-1, lBar)|StyleReverse, d.styles.Background)
+			}
+
+			for xCol := lStart; xCol < centerStart; xCol++ {
+				d.setCell(xCol, xRow, BarRune, d.barStyle(xCol, lBar), d.styles.Background)
 			}
 
-			for xCol := lStart; xCol < rStop; xCol++ {
-				termbox.SetCell(xCol, xRow, BarRune, d.styleBuffer[xCol], d.styles.Background)
+			for xCol := intMax(lStart, centerStart); xCol < rStop; xCol++ {
+				d.setCell(xCol, xRow, BarRune, d.barStyle(xCol, rBar), d.styles.Background)
 			}
 
 			if rCap < BarRuneH {
-				termbox.SetCell(rStop, xRow, rCap, d.styles.Foreground, d.styles.Foreground)
+				d.setCell(rStop, xRow, rCap, d.barStyle(rStop, rBar), d.styles.Foreground)
+			}
+		}
+	}
+}
+
+// DrawChroma will draw the first set of bins as labeled bars across the
+// whole width of the screen.
+func (d *Display) DrawChroma(bins [][]float64, count int, scale float64) {
+	if count <= 0 {
+		return
+	}
+
+	// leave the last row for labels.
+	barSpace := intMax(d.height()-1, 0)
+	scale = float64(barSpace) / scale
+
+	spaceWidth := d.spaceSize * (count - 1)
+	barWidth := intMax((d.width()-spaceWidth)/count, 1)
+	binWidth := barWidth + d.spaceSize
+
+	edgeOffset := intMax((d.width()-((binWidth*count)-d.spaceSize))/2, 0)
+
+	for xBar := 0; xBar < count; xBar++ {
+
+		start, bCap := sizeAndCap(bins[0][xBar]*scale, barSpace, true, BarRuneV)
+
+		pRow, peak := d.peakOffset(0, xBar, scale, barSpace)
+		pRow = barSpace - 1 - pRow
+		peak = peak && pRow < start-1
+
+		xCol := (xBar * binWidth) + edgeOffset
+		lCol := intMin(xCol+barWidth, d.width())
+
+		if xBar < len(d.labels) {
+			label := d.labels[xBar]
+			d.drawText(xCol+((barWidth-len(label))/2), barSpace, label)
+		}
+
+		for ; xCol < lCol; xCol++ {
+
+			if peak {
+				d.setCell(xCol, pRow, PeakRune, d.styles.Foreground, d.styles.Background)
+			}
+
+			if bCap > BarRuneV {
+				d.setCell(xCol, start-1, bCap, d.styles.Foreground, d.styles.Background)
+			}
+
+			for xRow := start; xRow < barSpace; xRow++ {
+				d.setCell(xCol, xRow, BarRune, d.styles.Foreground, d.styles.Background)
+			}
+		}
+	}
+}
+
+// DrawChromaStrip will draw the history of the first set of bins as a strip
+// scrolling from right to left, with one band of rows for each bin.
+func (d *Display) DrawChromaStrip(bins [][]float64, count int, scale float64) {
+	if count <= 0 {
+		return
+	}
+
+	labelWidth := 0
+	for _, label := range d.labels {
+		labelWidth = intMax(labelWidth, len(label)+1)
+	}
+
+	stripWidth := intMax(d.width()-labelWidth, 0)
+
+	d.history.push(bins[0][:count], count, stripWidth, 1.0/scale)
+
+	rowSize := intMax(d.height()/count, 1)
+	edgeOffset := intMax((d.height()-(rowSize*count))/2, 0)
+
+	shades := []rune(ShadeRunes)
+	maxShade := float64(len(shades) - 1)
+
+	for xBin := 0; xBin < count; xBin++ {
+
+		// draw higher bins at the top
+		xRow := ((count - 1 - xBin) * rowSize) + edgeOffset
+		lRow := intMin(xRow+rowSize, d.height())
+
+		if xBin < len(d.labels) {
+			d.drawText(0, xRow+((lRow-xRow)/2), d.labels[xBin])
+		}
+
+		for xCol := 0; xCol < stripWidth; xCol++ {
+
+			value, ok := d.history.at(stripWidth-1-xCol, xBin)
+			if !ok {
+				continue
+			}
+
+			shade := shades[int(math.Max(math.Min(value, 1.0), 0.0)*maxShade)]
+
+			for row := xRow; row < lRow; row++ {
+				d.setCell(xCol+labelWidth, row, shade, d.styles.Foreground, d.styles.Background)
 			}
 		}
 	}
 }
+
+func (d *Display) drawText(x, y int, text string) {
+	fg := d.styles.Text
+	if fg == termbox.ColorDefault {
+		fg = d.styles.Foreground
+	}
+
+	for _, r := range text {
+		d.setCell(x, y, r, fg, d.styles.Background)
+		x++
+	}
+}
+
+// setCell sets a cell in the area below the header. Cells outside of the
+// area are ignored.
+func (d *Display) setCell(x, y int, r rune, fg, bg termbox.Attribute) {
+	if x < 0 || x >= d.termWidth || y < 0 || y >= d.height() {
+		return
+	}
+
+	d.screenCell(x, y+d.headerHeight, r, fg, bg)
+}
+
+// screenCell sets a cell anywhere on the screen.
+func (d *Display) screenCell(x, y int, r rune, fg, bg termbox.Attribute) {
+	if d.truecolor {
+		fg, bg = trueColorCell(fg, bg)
+	}
+
+	d.renderer.SetCell(x, y, r, fg, bg)
+}
@@ -0,0 +1,556 @@
+package graphic
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// test styles, distinct so the style grid shows which is used where.
+var testStyles = Styles{
+	Foreground: termbox.Attribute(3),
+	Background: termbox.ColorDefault,
+	CenterLine: termbox.Attribute(7),
+}
+
+var testValues = []struct {
+	name string
+	fn   func(idx, count int) float64
+}{
+	{"zero", func(idx, count int) float64 { return 0 }},
+	{"ramp", func(idx, count int) float64 { return float64(idx+1) / float64(count) }},
+	{"over", func(idx, count int) float64 { return 1.5 }},
+	{"edge", func(idx, count int) float64 {
+		return []float64{math.NaN(), -1, math.Inf(1), 0.999, 1.0 / 16}[idx%5]
+	}},
+}
+
+var testGeometries = []struct {
+	width  int
+	height int
+	bar    int
+	space  int
+	base   int
+}{
+	{10, 6, 1, 0, 0},
+	{10, 6, 2, 1, 1},
+	{13, 7, 1, 1, 2},
+	{13, 7, 3, 2, 1},
+	{7, 9, 2, 0, 0},
+	{4, 3, 1, 1, 3},
+}
+
+// styleRune returns a rune for the style of a cell. Spaces are '.', upper
+// case means reversed.
+func styleRune(cell Cell) rune {
+	if cell.Ch == SpaceRune {
+		return '.'
+	}
+
+	var r rune
+	switch cell.Fg &^ StyleReverse {
+	case termbox.ColorDefault:
+		r = 'd'
+	case testStyles.Foreground:
+		r = 'f'
+	case testStyles.CenterLine:
+		r = 'c'
+	default:
+		r = '?'
+	}
+
+	if cell.Fg&StyleReverse != 0 {
+		r -= 'a' - 'A'
+	}
+
+	return r
+}
+
+func drawFrame(dt DrawType, width, height, bar, space, base int, value func(int, int) float64) string {
+	var buf = NewBuffer(width, height)
+	var d Display
+
+	d.SetRenderer(buf)
+	if err := d.Init(); err != nil {
+		panic(err)
+	}
+
+	d.SetSizes(bar, space)
+	d.SetBase(base)
+	d.SetDrawType(dt)
+	d.SetStyles(testStyles)
+
+	var count = d.Bars()
+	if dt == DrawUp || dt == DrawDown {
+		count = d.Bars(2)
+	}
+
+	var bins = [][]float64{make([]float64, count), make([]float64, count)}
+	for idx := 0; idx < count; idx++ {
+		bins[0][idx] = value(idx, count)
+		bins[1][idx] = value(count-1-idx, count)
+	}
+
+	if err := d.Draw(bins, 2, count, 1.0); err != nil {
+		panic(err)
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(buf.String())
+	sb.WriteString("--\n")
+
+	for _, row := range buf.Cells() {
+		for _, cell := range row {
+			sb.WriteRune(styleRune(cell))
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+func TestDrawGolden(t *testing.T) {
+	var drawTypes = []struct {
+		name string
+		dt   DrawType
+	}{
+		{"up", DrawUp},
+		{"down", DrawDown},
+		{"updown", DrawUpDown},
+		{"leftright", DrawLeftRight},
+	}
+
+	for _, tt := range drawTypes {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+
+			for _, g := range testGeometries {
+				for _, v := range testValues {
+					fmt.Fprintf(&sb, "== %dx%d bar %d space %d base %d %s ==\n",
+						g.width, g.height, g.bar, g.space, g.base, v.name)
+
+					sb.WriteString(drawFrame(tt.dt, g.width, g.height, g.bar, g.space, g.base, v.fn))
+				}
+			}
+
+			var path = filepath.Join("testdata", tt.name+".golden")
+
+			if *update {
+				if err := ioutil.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v, run with -update to create it", err)
+			}
+
+			if got := sb.String(); got != string(want) {
+				t.Errorf("frames differ from %s, run with -update if expected\n%s",
+					path, firstDiff(got, string(want)))
+			}
+		})
+	}
+}
+
+// firstDiff returns the first frame that differs, with its header.
+func firstDiff(got, want string) string {
+	var gotFrames = strings.Split(got, "== ")
+	var wantFrames = strings.Split(want, "== ")
+
+	for idx := range gotFrames {
+		if idx >= len(wantFrames) {
+			return "got extra frame:\n== " + gotFrames[idx]
+		}
+
+		if gotFrames[idx] != wantFrames[idx] {
+			return fmt.Sprintf("got:\n== %s\nwant:\n== %s", gotFrames[idx], wantFrames[idx])
+		}
+	}
+
+	return "missing frames"
+}
+
+func TestSizeAndCap(t *testing.T) {
+	var tests = []struct {
+		value    float64
+		space    int
+		zeroBase bool
+		size     int
+		cap      rune
+	}{
+		// bars growing up start at a row and cap the row above it.
+		{0, 4, true, 4, BarRuneV},
+		{0.125, 4, true, 4, BarRuneV + 1},
+		{1, 4, true, 3, BarRuneV},
+		{1.5, 4, true, 3, BarRuneV + 4},
+		{3.999, 4, true, 1, BarRuneV + 7},
+		{4, 4, true, 0, BarRuneV},
+		{9, 4, true, 0, BarRuneV},
+		{math.NaN(), 4, true, 4, BarRuneV},
+		{-2, 4, true, 4, BarRuneV},
+		{math.Inf(1), 4, true, 0, BarRuneV},
+
+		// bars growing down stop at a row and cap the row itself.
+		{0, 4, false, 0, BarRune},
+		{0.125, 4, false, 0, BarRune - 1},
+		{1, 4, false, 1, BarRune},
+		{2.5, 4, false, 2, BarRune - 4},
+		{4, 4, false, 4, BarRune},
+		{9, 4, false, 4, BarRune},
+		{math.NaN(), 4, false, 0, BarRune},
+		{-2, 4, false, 0, BarRune},
+		{math.Inf(1), 4, false, 4, BarRune},
+
+		// no space.
+		{1, 0, true, 0, BarRuneV},
+		{1, 0, false, 0, BarRune},
+	}
+
+	for _, tt := range tests {
+		size, bCap := sizeAndCap(tt.value, tt.space, tt.zeroBase, baseRune(tt.zeroBase))
+		if size != tt.size || bCap != tt.cap {
+			t.Errorf("sizeAndCap(%v, %d, %v) = %d, %U; want %d, %U",
+				tt.value, tt.space, tt.zeroBase, size, bCap, tt.size, tt.cap)
+		}
+	}
+}
+
+// drawColumn draws a single bar of value rows on a column of height rows and
+// returns the runes of the column from the top.
+func drawColumn(dt DrawType, height int, value float64) string {
+	var buf = NewBuffer(1, height)
+	var d Display
+
+	d.SetRenderer(buf)
+	if err := d.Init(); err != nil {
+		panic(err)
+	}
+
+	d.SetSizes(1, 0)
+	d.SetDrawType(dt)
+
+	if err := d.Draw([][]float64{{value}}, 1, 1, float64(height)); err != nil {
+		panic(err)
+	}
+
+	return strings.ReplaceAll(buf.String(), "\n", "")
+}
+
+// TestDrawCap checks where the cap of a bar goes: a bar of n whole rows has
+// no cap, and the eighths past the last whole row are capped in the row next
+// to it. A bar filling its space has no cap either.
+func TestDrawCap(t *testing.T) {
+	var tests = []struct {
+		value float64
+		up    string
+		down  string
+	}{
+		{0, "    ", "    "},
+		{0.1, "    ", "    "},
+		{0.125, "   ▁", "▇   "},
+		{1, "   █", "█   "},
+		{1.5, "  ▄█", "█▄  "},
+		{2.875, " ▇██", "██▁ "},
+		{3, " ███", "███ "},
+		{3.999, "▇███", "███▁"},
+		{4, "████", "████"},
+		{9, "████", "████"},
+		{math.Inf(1), "████", "████"},
+		{math.NaN(), "    ", "    "},
+		{-2, "    ", "    "},
+	}
+
+	for _, tt := range tests {
+		if got := drawColumn(DrawUp, 4, tt.value); got != tt.up {
+			t.Errorf("up %v: got %q, want %q", tt.value, got, tt.up)
+		}
+
+		// caps of bars growing down are reversed, so the lower block rune
+		// shows the remaining eighths at the top of the cell.
+		if got := drawColumn(DrawDown, 4, tt.value); got != tt.down {
+			t.Errorf("down %v: got %q, want %q", tt.value, got, tt.down)
+		}
+	}
+}
+
+func baseRune(zeroBase bool) rune {
+	if zeroBase {
+		return BarRuneV
+	}
+
+	return BarRune
+}
+
+func TestBufferFlush(t *testing.T) {
+	var buf = NewBuffer(3, 2)
+
+	buf.SetCell(1, 1, 'x', 0, 0)
+	buf.SetCell(3, 0, 'y', 0, 0)
+	buf.SetCell(-1, 0, 'y', 0, 0)
+
+	if got, want := buf.String(), "   \n   \n"; got != want {
+		t.Errorf("before flush got %q, want %q", got, want)
+	}
+
+	buf.Flush()
+
+	if got, want := buf.String(), "   \n x \n"; got != want {
+		t.Errorf("after flush got %q, want %q", got, want)
+	}
+
+	buf.Resize(2, 1)
+
+	if ev := buf.PollEvent(); ev.Type != EventResize || ev.Width != 2 || ev.Height != 1 {
+		t.Errorf("got event %+v, want a 2x1 resize", ev)
+	}
+}
+
+func TestDrawVectorCorners(t *testing.T) {
+	// 3 columns are 6 dots wide, which is a row and a half of dots high.
+	var buf = NewBuffer(3, 4)
+	var d Display
+
+	d.SetRenderer(buf)
+	if err := d.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	d.SetDrawType(DrawLissajous)
+	d.SetSamples([][]float64{{-1, 1}, {-1, 1}})
+
+	if err := d.Draw(nil, 2, 0, 1.0); err != nil {
+		t.Fatal(err)
+	}
+
+	// the top right and bottom left corners.
+	var want = []rune{BrailleRune + brailleBits[1][0], BrailleRune + brailleBits[0][1]}
+	var got []rune
+
+	for _, row := range buf.Cells() {
+		for _, cell := range row {
+			if cell.Ch != ' ' && cell.Ch != 0 {
+				got = append(got, cell.Ch)
+			}
+		}
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("got %q, want %q", string(got), string(want))
+	}
+}
+
+func TestSetBarCell(t *testing.T) {
+	var tests = []struct {
+		part    cellPart
+		ch      rune
+		reverse bool
+	}{
+		{cellPart{0, 8}, BarRune, false},
+		{cellPart{0, 3}, leftRune(3), false},
+		{cellPart{5, 8}, leftRune(5), true},
+		// parts in the middle keep their width on the nearer side.
+		{cellPart{2, 5}, leftRune(3), false},
+		{cellPart{4, 7}, leftRune(5), true},
+		{cellPart{1, 7}, leftRune(6), false},
+	}
+
+	for _, tt := range tests {
+		var buf = NewBuffer(1, 1)
+		var d Display
+
+		d.SetRenderer(buf)
+		if err := d.Init(); err != nil {
+			t.Fatal(err)
+		}
+
+		d.setBarCell(0, 0, tt.part, 0, 0)
+		buf.Flush()
+
+		var cell = buf.Cells()[0][0]
+		if reverse := cell.Fg&StyleReverse != 0; cell.Ch != tt.ch || reverse != tt.reverse {
+			t.Errorf("part %v: got %q reversed %v, want %q reversed %v",
+				tt.part, cell.Ch, reverse, tt.ch, tt.reverse)
+		}
+	}
+}
+
+func TestCycleTheme(t *testing.T) {
+	var d Display
+	var flags = Gradient{{R: 1, G: 2, B: 3}, {R: 4, G: 5, B: 6}}
+
+	d.SetGradient(GradientHeight, flags)
+	d.SetThemes(BuiltinThemes)
+
+	// the first step back from no theme is the last theme.
+	d.CycleTheme(-1)
+	if got, want := d.Theme(), BuiltinThemes[len(BuiltinThemes)-1].Name; got != want {
+		t.Errorf("got theme %q, want %q", got, want)
+	}
+
+	d.CycleTheme(1)
+	if got := d.Theme(); got != "default" {
+		t.Errorf("got theme %q, want default", got)
+	}
+
+	d.CycleTheme(1)
+	if got := d.Theme(); got != "nord" || reflect.DeepEqual(d.gradient, flags) {
+		t.Fatalf("got theme %q with gradient %v, want nord with its own", got, d.gradient)
+	}
+
+	// default has no gradient, so the one given by flags is back.
+	d.CycleTheme(-1)
+	if !reflect.DeepEqual(d.gradient, flags) {
+		t.Errorf("got gradient %v, want %v", d.gradient, flags)
+	}
+}
+
+func TestThemeColors(t *testing.T) {
+	// themes and gradients take the same colors.
+	for _, s := range []string{"#ff8000", "#f80", " 208 ", "0", "255"} {
+		if _, err := ParseColor(s); err != nil {
+			t.Errorf("gradient stop %q: %v", s, err)
+		}
+
+		if _, err := parseThemeColor(s); err != nil {
+			t.Errorf("theme color %q: %v", s, err)
+		}
+	}
+
+	for _, s := range []string{"ff8000", "#ff80", "256", "-1", "red"} {
+		if _, err := ParseColor(s); err == nil {
+			t.Errorf("gradient stop %q did not fail", s)
+		}
+
+		if _, err := parseThemeColor(s); err == nil {
+			t.Errorf("theme color %q did not fail", s)
+		}
+	}
+
+	// only themes can leave a color to the terminal.
+	if _, err := ParseGradient([]string{"#000", "default"}); err == nil {
+		t.Error("gradient with a default stop did not fail")
+	}
+
+	if c, err := parseThemeColor("default"); err != nil || c.rgb || c.index != 0 {
+		t.Errorf("default parsed to %+v, %v", c, err)
+	}
+
+	if c, _ := ParseColor("208"); c != xtermPalette[208] {
+		t.Errorf("208 parsed to %v, want %v", c, xtermPalette[208])
+	}
+}
+
+func TestDrawWidths(t *testing.T) {
+	var tests = []struct {
+		dt      DrawType
+		braille bool
+		fill    bool
+	}{
+		{DrawUp, false, false},
+		{DrawDown, false, false},
+		{DrawUpDown, false, false},
+		{DrawUp, true, false},
+		{DrawUpDown, true, false},
+		{DrawUp, false, true},
+	}
+
+	for _, tt := range tests {
+		var buf = NewBuffer(13, 6)
+		var d Display
+
+		d.SetRenderer(buf)
+		if err := d.Init(); err != nil {
+			t.Fatal(err)
+		}
+
+		d.SetSizes(1, 1)
+		d.SetDrawType(tt.dt)
+		d.SetBraille(tt.braille)
+		d.SetFill(tt.fill)
+		d.SetStereo(&Stereo{Widths: []float64{0.5, 1}})
+
+		var bins = [][]float64{{1, 1}, {1, 1}}
+		if err := d.Draw(bins, 2, 2, 1.0); err != nil {
+			t.Fatal(err)
+		}
+
+		// the widths are over the bars, and nowhere else.
+		var rows = strings.Split(buf.String(), "\n")
+		var widths, bars = []rune(rows[1]), []rune(rows[3])
+
+		for xCol := range widths {
+			if (widths[xCol] != ' ') != (bars[xCol] != ' ') {
+				t.Errorf("draw type %d braille %v fill %v: widths %q over bars %q",
+					tt.dt, tt.braille, tt.fill, rows[1], rows[3])
+				break
+			}
+		}
+	}
+
+	// without bars in columns the bands are stretched.
+	var buf = NewBuffer(11, 6)
+	var d Display
+
+	d.SetRenderer(buf)
+	if err := d.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	d.SetDrawType(DrawLeftRight)
+	d.SetStereo(&Stereo{Widths: []float64{0.5, 1}})
+
+	if err := d.Draw([][]float64{{0, 0}, {0, 0}}, 2, 2, 1.0); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := strings.Split(buf.String(), "\n")[1], "▒▒▒▒▒▒█████"; got != want {
+		t.Errorf("left right got %q, want %q", got, want)
+	}
+}
+
+func TestDrawStereoHeader(t *testing.T) {
+	var buf = NewBuffer(40, 6)
+	var d Display
+
+	d.SetRenderer(buf)
+	if err := d.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	d.SetDrawType(DrawUp)
+	d.SetStereo(&Stereo{Correlation: 1, Balance: -0.5, MidSide: 12})
+
+	if err := d.Draw([][]float64{{0}, {0}}, 2, 1, 1.0); err != nil {
+		t.Fatal(err)
+	}
+
+	var header = strings.Split(buf.String(), "\n")[0]
+	if want := " +1 M/S +12.0 dB  L/R -0.50"; !strings.HasSuffix(header, want) {
+		t.Errorf("header %q, want it to end with %q", header, want)
+	}
+
+	// without room for the values, only the bar is drawn.
+	buf.Resize(12, 6)
+	d.termWidth = 12
+
+	if err := d.Draw([][]float64{{0}, {0}}, 2, 1, 1.0); err != nil {
+		t.Fatal(err)
+	}
+
+	if header := strings.Split(buf.String(), "\n")[0]; !strings.HasSuffix(header, " +1") {
+		t.Errorf("narrow header %q, want only the bar", header)
+	}
+}
@@ -0,0 +1,126 @@
+package graphic
+
+import (
+	"math"
+
+	"github.com/nsf/termbox-go"
+)
+
+// SetFill sets if bars are stretched to fill the whole width. Bars and spaces
+// keep their ratio and are drawn in eighths of a cell, so there is no margin
+// left over whatever the bar count. Only bars that grow vertically are
+// stretched.
+func (d *Display) SetFill(fill bool) {
+	d.fill = fill
+}
+
+// Fill returns true if bars are stretched to fill the whole width.
+func (d *Display) Fill() bool {
+	return d.fill
+}
+
+// barSpan is the span of a bar in eighths of a cell.
+type barSpan struct {
+	start int
+	stop  int
+}
+
+// cellPart is the part of a cell covered by a bar in eighths of a cell from
+// the left.
+type cellPart struct {
+	lo int
+	hi int
+}
+
+func (p cellPart) full() bool {
+	return p.lo == 0 && p.hi == NumRunes
+}
+
+// half returns true if at least half of the cell is covered. Caps and peaks
+// are only drawn in cells that are mostly covered.
+func (p cellPart) half() bool {
+	return p.hi-p.lo >= NumRunes/2
+}
+
+// leftRune returns the rune with the left eighths of a cell filled.
+func leftRune(eighths int) rune {
+	return BarRune + rune(NumRunes-eighths)
+}
+
+// resetCover forgets which bar covers each column.
+func (d *Display) resetCover() {
+	if cap(d.cover) < d.width() {
+		d.cover = make([]int, d.width())
+	}
+
+	d.cover = d.cover[:d.width()]
+	for i := range d.cover {
+		d.cover[i] = 0
+	}
+}
+
+// barSpan returns the span of the bar at pos out of total bars across the
+// width. xCol and lCol are the columns of the bar when not filling.
+func (d *Display) barSpan(pos, total, xCol, lCol int) barSpan {
+	if !d.fill || total <= 0 {
+		return barSpan{start: xCol * NumRunes, stop: lCol * NumRunes}
+	}
+
+	bin := float64(d.width()*NumRunes) / float64(total)
+	size := math.Round(bin * float64(d.barSize) / float64(d.binSize))
+
+	start := int(math.Round(float64(pos) * bin))
+	stop := intMin(start+intMax(int(size), 1), d.width()*NumRunes)
+
+	return barSpan{start: start, stop: stop}
+}
+
+// first returns the first column of the span.
+func (s barSpan) first() int {
+	return s.start / NumRunes
+}
+
+// last returns the column after the last column of the span.
+func (s barSpan) last() int {
+	return (s.stop + NumRunes - 1) / NumRunes
+}
+
+// cellPart returns the part of the column xCol covered by the span. When two
+// bars share a column, the one covering more of it is drawn.
+func (d *Display) cellPart(s barSpan, xCol int) (cellPart, bool) {
+	part := cellPart{
+		lo: intMax(s.start-(xCol*NumRunes), 0),
+		hi: intMin(s.stop-(xCol*NumRunes), NumRunes),
+	}
+
+	if !d.fill {
+		return part, true
+	}
+
+	if xCol < 0 || xCol >= len(d.cover) || part.hi-part.lo < d.cover[xCol] {
+		return part, false
+	}
+
+	d.cover[xCol] = part.hi - part.lo
+
+	return part, true
+}
+
+// setBarCell sets the part of a bar cell. A part in the middle of a cell has
+// no rune, so it is moved to the nearer side and keeps its width.
+func (d *Display) setBarCell(x, y int, part cellPart, fg, bg termbox.Attribute) {
+	switch {
+	case part.full():
+		d.setCell(x, y, BarRune, fg, bg)
+
+	case part.lo == 0:
+		d.setCell(x, y, leftRune(part.hi), fg, bg)
+
+	case part.hi < NumRunes && part.lo <= NumRunes-part.hi:
+		d.setCell(x, y, leftRune(part.hi-part.lo), fg, bg)
+
+	default:
+		// the right side of a cell is the reverse of the left side.
+		d.setCell(x, y, leftRune(NumRunes-(part.hi-part.lo)), fg|StyleReverse, bg)
+	}
+}
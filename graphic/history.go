@@ -0,0 +1,64 @@
+package graphic
+
+// history is a ring buffer of columns of values, used by draw types that
+// scroll through time.
+type history struct {
+	data  []float64
+	rows  int
+	cols  int
+	index int // index of the next column to write
+	count int // number of columns written
+}
+
+// push scales values and writes them as the newest column. The history is
+// reset if the number of rows or columns changed.
+func (h *history) push(values []float64, rows, cols int, scale float64) {
+	if rows != h.rows || cols != h.cols {
+		h.resize(rows, cols)
+	}
+
+	if h.cols <= 0 || h.rows <= 0 {
+		return
+	}
+
+	column := h.data[h.index*h.rows : (h.index+1)*h.rows]
+	for idx := range column {
+		column[idx] = values[idx] * scale
+	}
+
+	if h.index++; h.index >= h.cols {
+		h.index = 0
+	}
+
+	if h.count < h.cols {
+		h.count++
+	}
+}
+
+// at returns the value at row, age columns ago. ok is false if there is no
+// column that old.
+func (h *history) at(age, row int) (float64, bool) {
+	if age < 0 || age >= h.count || row < 0 || row >= h.rows {
+		return 0, false
+	}
+
+	col := h.index - 1 - age
+	if col < 0 {
+		col += h.cols
+	}
+
+	return h.data[(col*h.rows)+row], true
+}
+
+func (h *history) resize(rows, cols int) {
+	h.rows = rows
+	h.cols = cols
+	h.index = 0
+	h.count = 0
+
+	if size := rows * cols; cap(h.data) < size {
+		h.data = make([]float64, size)
+	} else {
+		h.data = h.data[:size]
+	}
+}
@@ -0,0 +1,98 @@
+package graphic
+
+// Peak runes mark the peak of meters and bars.
+const (
+	PeakRune  = '\u2500'
+	PeakRuneH = '\u2502'
+)
+
+// Meter is a single level drawn in the meter column.
+type Meter struct {
+	Label string  // drawn below the level
+	Level float64 // bar level in the range [0, 1]
+	Peak  float64 // peak marker in the range [0, 1], drawn if above Level
+}
+
+// SetMeters sets the meters and the lines of text drawn in a column on the
+// right side of the screen. Text is drawn above the meters. The column is
+// removed if both are empty.
+func (d *Display) SetMeters(meters []Meter, text []string) {
+	d.meters = meters
+	d.meterText = text
+
+	width := 2 * len(meters)
+	for _, line := range text {
+		width = intMax(width, len(line))
+	}
+
+	// leave a gap between the bars and the column.
+	if width > 0 {
+		width++
+	}
+
+	if width != d.meterWidth {
+		d.meterWidth = width
+		d.updateStyleBuffer()
+	}
+}
+
+func (d *Display) drawMeters() {
+	if d.meterWidth <= 0 {
+		return
+	}
+
+	xStart := d.width() + 1
+
+	for xRow, line := range d.meterText {
+		d.drawText(xStart, xRow, line)
+	}
+
+	if len(d.meters) == 0 {
+		return
+	}
+
+	// meters are below the text, with one blank row, and above the labels.
+	top := len(d.meterText)
+	if top > 0 {
+		top++
+	}
+
+	labelRow := d.height() - 1
+	barSpace := intMax(labelRow-top, 0)
+
+	for xMeter, m := range d.meters {
+		xCol := xStart + (xMeter * 2)
+
+		d.drawText(xCol, labelRow, m.Label)
+
+		start, bCap := sizeAndCap(clamp(m.Level)*float64(barSpace), barSpace, true, BarRuneV)
+
+		if bCap > BarRuneV {
+			d.setCell(xCol, top+start-1, bCap, d.styles.Foreground, d.styles.Background)
+		}
+
+		for xRow := start; xRow < barSpace; xRow++ {
+			d.setCell(xCol, top+xRow, BarRune, d.styles.Foreground, d.styles.Background)
+		}
+
+		if m.Peak > m.Level {
+			pRow := barSpace - int(clamp(m.Peak)*float64(barSpace))
+			if pRow < start-1 {
+				d.setCell(xCol, top+intMax(pRow, 0), PeakRune, d.styles.CenterLine, d.styles.Background)
+			}
+		}
+	}
+}
+
+// clamp limits value to the range [0, 1].
+func clamp(value float64) float64 {
+	switch {
+	case value > 1.0:
+		return 1.0
+	case value > 0.0:
+		return value
+	default:
+		// also catches NaN
+		return 0.0
+	}
+}
@@ -0,0 +1,315 @@
+package graphic
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/nsf/termbox-go"
+)
+
+// ImageCanvas is a canvas that can show an image over its cells.
+type ImageCanvas interface {
+	Canvas
+	// CellSize returns the size of a cell in pixels, zero if unknown.
+	CellSize() (int, int)
+	// SetImage shows img with its top left corner on the cell at column x
+	// and row y from the next flush on. A nil image removes it.
+	SetImage(x, y int, img *image.NRGBA)
+}
+
+// PixelProtocol is the terminal graphics protocol images are sent with.
+type PixelProtocol int
+
+// pixel protocols
+const (
+	PixelNone PixelProtocol = iota
+	PixelKitty
+	PixelSixel
+	PixelMax
+)
+
+// kittyChunk is the most base64 data sent in one kitty escape.
+const kittyChunk = 4096
+
+// kittyID is the id of the image and placement the display replaces.
+const kittyID = 1
+
+// Pixel renders cells with termbox and draws images with a terminal graphics
+// protocol on top of them.
+type Pixel struct {
+	Termbox
+
+	Protocol PixelProtocol
+
+	tty   *os.File
+	out   io.Writer
+	img   *image.NRGBA
+	x     int
+	y     int
+	shown bool
+	buf   bytes.Buffer
+	zbuf  bytes.Buffer
+}
+
+// NewPixel returns a renderer that draws images with protocol.
+func NewPixel(protocol PixelProtocol) *Pixel {
+	return &Pixel{Protocol: protocol}
+}
+
+// Init initializes termbox and opens the terminal images are written to.
+func (p *Pixel) Init(truecolor bool) error {
+	if err := p.Termbox.Init(truecolor); err != nil {
+		return err
+	}
+
+	// termbox writes to the terminal even if stdout is redirected, so do we.
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		p.tty = tty
+		p.out = tty
+	} else {
+		p.tty = os.Stdout
+		p.out = os.Stdout
+	}
+
+	return nil
+}
+
+// Close removes the image and closes termbox.
+func (p *Pixel) Close() error {
+	if p.shown && p.Protocol == PixelKitty {
+		fmt.Fprintf(p.out, "\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", kittyID)
+	}
+
+	if p.tty != nil && p.tty != os.Stdout {
+		p.tty.Close()
+	}
+
+	return p.Termbox.Close()
+}
+
+// CellSize returns the size of a cell in pixels.
+func (p *Pixel) CellSize() (int, int) {
+	if p.tty == nil {
+		return 0, 0
+	}
+
+	return cellSize(p.tty)
+}
+
+// SetImage sets the image drawn on the next flush.
+func (p *Pixel) SetImage(x, y int, img *image.NRGBA) {
+	p.img, p.x, p.y = img, x, y
+}
+
+// Flush draws the cells, then the image over them.
+func (p *Pixel) Flush() error {
+	if err := p.Termbox.Flush(); err != nil {
+		return err
+	}
+
+	if p.img == nil {
+		if p.shown {
+			p.shown = false
+			return p.clear()
+		}
+
+		return nil
+	}
+
+	p.buf.Reset()
+
+	// termbox keeps track of the cursor, so put it back where it was.
+	p.buf.WriteString("\x1b7")
+	fmt.Fprintf(&p.buf, "\x1b[%d;%dH", p.y+1, p.x+1)
+
+	switch p.Protocol {
+	case PixelKitty:
+		if err := p.encodeKitty(); err != nil {
+			return err
+		}
+	case PixelSixel:
+		encodeSixel(&p.buf, p.img)
+	}
+
+	p.buf.WriteString("\x1b8")
+
+	p.shown = true
+
+	_, err := p.out.Write(p.buf.Bytes())
+	return err
+}
+
+// clear removes the image shown.
+func (p *Pixel) clear() error {
+	switch p.Protocol {
+	case PixelKitty:
+		_, err := fmt.Fprintf(p.out, "\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", kittyID)
+		return err
+
+	default:
+		// sixels are part of the screen, the cells under them are drawn
+		// again to remove them.
+		return termbox.Sync()
+	}
+}
+
+// encodeKitty adds the image to the buffer as compressed rgba. Sending it
+// with the same id and placement replaces the image shown before.
+func (p *Pixel) encodeKitty() error {
+	var img = p.img
+	var bounds = img.Bounds()
+
+	p.zbuf.Reset()
+
+	var zw = zlib.NewWriter(&p.zbuf)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		var row = img.Pix[img.PixOffset(bounds.Min.X, y):img.PixOffset(bounds.Max.X, y)]
+		if _, err := zw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	var data = base64.StdEncoding.EncodeToString(p.zbuf.Bytes())
+
+	for first := true; first || len(data) > 0; first = false {
+		var chunk = data
+		if len(chunk) > kittyChunk {
+			chunk = chunk[:kittyChunk]
+		}
+		data = data[len(chunk):]
+
+		var more = 0
+		if len(data) > 0 {
+			more = 1
+		}
+
+		p.buf.WriteString("\x1b_G")
+		if first {
+			fmt.Fprintf(&p.buf, "a=T,f=32,o=z,s=%d,v=%d,i=%d,p=%d,q=2,C=1,",
+				bounds.Dx(), bounds.Dy(), kittyID, kittyID)
+		}
+
+		fmt.Fprintf(&p.buf, "m=%d;%s\x1b\\", more, chunk)
+	}
+
+	return nil
+}
+
+// sixelLevels is the number of levels of each channel in the sixel palette.
+const sixelLevels = 6
+
+// sixelColor returns the palette index of a pixel, or -1 if it is mostly
+// transparent. Sixels have no alpha, so edges are not blended.
+func sixelColor(img *image.NRGBA, x, y int) int {
+	var i = img.PixOffset(x, y)
+	var pix = img.Pix[i : i+4 : i+4]
+
+	if pix[3] < 128 {
+		return -1
+	}
+
+	var level = func(v uint8) int {
+		return (int(v)*(sixelLevels-1) + 127) / 255
+	}
+
+	return (level(pix[0]) * sixelLevels * sixelLevels) + (level(pix[1]) * sixelLevels) + level(pix[2])
+}
+
+// encodeSixel adds the image to the buffer as sixels. Transparent pixels are
+// drawn in the background color so the previous image does not show.
+func encodeSixel(buf *bytes.Buffer, img *image.NRGBA) {
+	var bounds = img.Bounds()
+	var width = bounds.Dx()
+
+	fmt.Fprintf(buf, "\x1bP0;0;0q\"1;1;%d;%d", width, bounds.Dy())
+
+	const colors = sixelLevels * sixelLevels * sixelLevels
+
+	var defined [colors]bool
+	var bits = make([]byte, width)
+	var used [colors]bool
+
+	for top := bounds.Min.Y; top < bounds.Max.Y; top += 6 {
+		var bottom = top + 6
+		if bottom > bounds.Max.Y {
+			bottom = bounds.Max.Y
+		}
+
+		used = [colors]bool{}
+		for y := top; y < bottom; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if c := sixelColor(img, x, y); c >= 0 {
+					used[c] = true
+				}
+			}
+		}
+
+		for c := range used {
+			if !used[c] {
+				continue
+			}
+
+			if !defined[c] {
+				defined[c] = true
+
+				var r = (c / (sixelLevels * sixelLevels)) * 100 / (sixelLevels - 1)
+				var g = ((c / sixelLevels) % sixelLevels) * 100 / (sixelLevels - 1)
+				var b = (c % sixelLevels) * 100 / (sixelLevels - 1)
+
+				fmt.Fprintf(buf, "#%d;2;%d;%d;%d", c, r, g, b)
+			}
+
+			for x := range bits {
+				bits[x] = 0
+				for y := top; y < bottom; y++ {
+					if sixelColor(img, bounds.Min.X+x, y) == c {
+						bits[x] |= 1 << uint(y-top)
+					}
+				}
+			}
+
+			buf.WriteByte('#')
+			buf.WriteString(strconv.Itoa(c))
+			writeSixels(buf, bits)
+			buf.WriteByte('$')
+		}
+
+		buf.WriteByte('-')
+	}
+
+	buf.WriteString("\x1b\\")
+}
+
+// writeSixels writes a row of sixels with runs compressed.
+func writeSixels(buf *bytes.Buffer, bits []byte) {
+	for x := 0; x < len(bits); {
+		var run = 1
+		for x+run < len(bits) && bits[x+run] == bits[x] {
+			run++
+		}
+
+		var ch = byte('?' + bits[x])
+
+		if run > 3 {
+			buf.WriteByte('!')
+			buf.WriteString(strconv.Itoa(run))
+			buf.WriteByte(ch)
+		} else {
+			for i := 0; i < run; i++ {
+				buf.WriteByte(ch)
+			}
+		}
+
+		x += run
+	}
+}
@@ -0,0 +1,12 @@
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd
+
+package graphic
+
+import (
+	"os"
+)
+
+// cellSize returns zero, the pixel size of the terminal is not known here.
+func cellSize(f *os.File) (int, int) {
+	return 0, 0
+}
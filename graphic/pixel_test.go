@@ -0,0 +1,168 @@
+package graphic
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"image"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// imageBuffer is a buffer that takes images, with cells of 4 by 8 pixels.
+type imageBuffer struct {
+	*Buffer
+	img *image.NRGBA
+}
+
+func (b *imageBuffer) CellSize() (int, int) {
+	return 4, 8
+}
+
+func (b *imageBuffer) SetImage(x, y int, img *image.NRGBA) {
+	b.img = img
+}
+
+func TestDrawPixels(t *testing.T) {
+	var buf = &imageBuffer{Buffer: NewBuffer(4, 3)}
+	var d Display
+
+	d.SetRenderer(buf)
+	if err := d.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	d.SetSizes(1, 1)
+	d.SetBase(1)
+	d.SetDrawType(DrawUp)
+	d.SetStyles(testStyles)
+
+	// 2 bars per channel over 16 pixels, each 2 pixels wide and centered in
+	// 4 pixels. 16 pixels of space over the base.
+	var bins = [][]float64{{1, 0.5}, {0.25 + (1.0 / 32), 0}}
+	if err := d.Draw(bins, 2, 2, 1.0); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.img == nil {
+		t.Fatal("no image drawn")
+	}
+
+	var alpha = func(x, y int) uint8 {
+		return buf.img.NRGBAAt(x, y).A
+	}
+
+	var tests = []struct {
+		x, y int
+		a    uint8
+	}{
+		{1, 0, 255},   // the full bar
+		{0, 0, 0},     // space before it
+		{4, 0, 0},     // space after it
+		{5, 8, 255},   // half bar
+		{5, 7, 0},     // above half bar
+		{13, 12, 255}, // mirrored, a quarter bar and half a pixel
+		{13, 11, 128}, // the half pixel
+		{9, 15, 0},    // empty bar
+		{9, 16, 255},  // the base
+		{3, 23, 255},  // the base
+	}
+
+	for _, tt := range tests {
+		if got := alpha(tt.x, tt.y); got != tt.a {
+			t.Errorf("alpha at %d, %d = %d; want %d", tt.x, tt.y, got, tt.a)
+		}
+	}
+
+	// the cells under the image stay empty.
+	if got := strings.TrimSpace(buf.String()); got != "" {
+		t.Errorf("cells drawn under the image: %q", got)
+	}
+}
+
+func TestWriteSixels(t *testing.T) {
+	var buf bytes.Buffer
+
+	writeSixels(&buf, []byte{0, 0, 1, 1, 1, 1, 1, 63, 2})
+
+	if got, want := buf.String(), "??!5@~A"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEncodeSixel(t *testing.T) {
+	var img = image.NewNRGBA(image.Rect(0, 0, 3, 7))
+	for y := 0; y < 7; y++ {
+		img.Pix[img.PixOffset(1, y)+0] = 255
+		img.Pix[img.PixOffset(1, y)+3] = 255
+	}
+
+	var buf bytes.Buffer
+	encodeSixel(&buf, img)
+
+	// red is 5*36 in the palette, a full column, then a row of one pixel.
+	var want = "\x1bP0;0;0q\"1;1;3;7#180;2;100;0;0#180?~?$-#180?@?$-\x1b\\"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEncodeKitty(t *testing.T) {
+	var img = image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	// noise, so it does not compress into a single chunk.
+	var seed uint32 = 1
+	for i := range img.Pix {
+		seed = (seed * 1664525) + 1013904223
+		img.Pix[i] = uint8(seed >> 24)
+	}
+
+	var p = Pixel{Protocol: PixelKitty, img: img}
+	if err := p.encodeKitty(); err != nil {
+		t.Fatal(err)
+	}
+
+	var escapes = regexp.MustCompile("\x1b_G([^;]*);([^\x1b]*)\x1b\\\\").FindAllStringSubmatch(p.buf.String(), -1)
+	if len(escapes) < 2 {
+		t.Fatalf("got %d escapes, want the image in chunks", len(escapes))
+	}
+
+	var data strings.Builder
+	for idx, esc := range escapes {
+		var last = idx == len(escapes)-1
+
+		if strings.HasSuffix(esc[1], "m=1") == last {
+			t.Errorf("chunk %d has keys %q", idx, esc[1])
+		}
+
+		if len(esc[2]) > kittyChunk {
+			t.Errorf("chunk %d is %d bytes", idx, len(esc[2]))
+		}
+
+		data.WriteString(esc[2])
+	}
+
+	if !strings.HasPrefix(escapes[0][1], "a=T,f=32,o=z,s=64,v=64,") {
+		t.Errorf("first chunk has keys %q", escapes[0][1])
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(data.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pix, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(pix, img.Pix) {
+		t.Error("decoded pixels differ")
+	}
+}
@@ -0,0 +1,31 @@
+// +build linux darwin freebsd netbsd openbsd
+
+package graphic
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+type winsize struct {
+	rows   uint16
+	cols   uint16
+	width  uint16
+	height uint16
+}
+
+// cellSize returns the size of a cell of the terminal f in pixels, zero if
+// the terminal does not say.
+func cellSize(f *os.File) (int, int) {
+	var ws winsize
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL,
+		f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+
+	if errno != 0 || ws.rows == 0 || ws.cols == 0 {
+		return 0, 0
+	}
+
+	return int(ws.width / ws.cols), int(ws.height / ws.rows)
+}
@@ -0,0 +1,281 @@
+package graphic
+
+import (
+	"image"
+	"math"
+
+	"github.com/nsf/termbox-go"
+)
+
+// PixelForeground is the color drawn in pixels for the default foreground,
+// as the terminal does not tell what it is.
+var PixelForeground = Color{R: 208, G: 208, B: 208}
+
+// pixelCanvas returns the renderer if the bars of the current draw type are
+// drawn in pixels.
+func (d *Display) pixelCanvas() (ImageCanvas, bool) {
+	canvas, ok := d.renderer.(ImageCanvas)
+	if !ok {
+		return nil, false
+	}
+
+	switch d.drawType {
+	case DrawUp, DrawDown, DrawUpDown, DrawLeftRight:
+	default:
+		return nil, false
+	}
+
+	if cw, ch := canvas.CellSize(); cw <= 0 || ch <= 0 {
+		return nil, false
+	}
+
+	return canvas, true
+}
+
+// attributeColor returns the rgb color of an attribute, or def for the
+// default color.
+func attributeColor(a termbox.Attribute, def Color) Color {
+	if a >= termbox.RGBToAttribute(0, 0, 0) {
+		r, g, b := termbox.AttributeToRGB(a)
+		return Color{R: r, G: g, B: b}
+	}
+
+	var idx = int(a & 0x1FF)
+	if idx == 0 || idx > len(xtermPalette) {
+		return def
+	}
+
+	return xtermPalette[idx-1]
+}
+
+// pixelColor returns the color of the bar of bin xBin out of count in set
+// xSet when the gradient runs across bars, or the foreground otherwise.
+func (d *Display) pixelColor(xSet, xBin, count int) Color {
+	switch d.gradientMode {
+	case GradientFrequency:
+		return d.gradient.At(float64(xBin) / float64(intMax(count-1, 1)))
+	case GradientChannel:
+		return d.gradient.At(float64(xSet))
+	default:
+		return attributeColor(d.styles.Foreground, PixelForeground)
+	}
+}
+
+// pixelBar is a bar drawn in pixels. Bars cover [start, stop) across and grow
+// from base by length in dir along, out of space.
+type pixelBar struct {
+	start    float64
+	stop     float64
+	base     float64
+	length   float64
+	peak     float64
+	hasPeak  bool
+	dir      float64
+	space    float64
+	vertical bool
+	color    Color
+}
+
+// DrawPixels will draw the bars of the current draw type as an image on the
+// canvas, with edges blended and peaks between pixels.
+func (d *Display) DrawPixels(canvas ImageCanvas, bins [][]float64, count int, scale float64) {
+	cw, ch := canvas.CellSize()
+	w, h := d.width()*cw, d.height()*ch
+
+	if d.pixels == nil || d.pixels.Rect.Dx() != w || d.pixels.Rect.Dy() != h {
+		d.pixels = image.NewNRGBA(image.Rect(0, 0, w, h))
+	} else {
+		for i := range d.pixels.Pix {
+			d.pixels.Pix[i] = 0
+		}
+	}
+
+	setCount := len(bins)
+	center := attributeColor(d.styles.CenterLine, PixelForeground)
+
+	// bars are spread across the whole width, the space split around them.
+	across := func(pos, total int, size float64) (float64, float64) {
+		bin := size / float64(intMax(total, 1))
+		bar := bin * float64(d.barSize) / float64(d.binSize)
+		start := (float64(pos) * bin) + ((bin - bar) / 2)
+		return start, start + bar
+	}
+
+	bar := func(xSet, xBin int, value, space float64) pixelBar {
+		b := pixelBar{
+			length: math.Max(math.Min(value*space/scale, space), 0),
+			space:  space,
+			color:  d.pixelColor(xSet, xBin, count),
+		}
+
+		if b.length != b.length {
+			b.length = 0
+		}
+
+		if xSet < len(d.peaks) {
+			b.peak = math.Max(math.Min(d.peaks[xSet][xBin]*space/scale, space), 0)
+			b.hasPeak = b.peak > b.length
+		}
+
+		return b
+	}
+
+	switch d.drawType {
+	case DrawUp, DrawDown:
+		baseSize := float64(d.baseSize * ch)
+		space := float64(h) - baseSize
+
+		for xSet, chBins := range bins {
+			for xBar := 0; xBar < count; xBar++ {
+				xBin := (xBar * (1 - xSet)) + (((count - 1) - xBar) * xSet)
+
+				b := bar(xSet, xBin, chBins[xBin], space)
+				b.start, b.stop = across(xBar+(count*xSet), count*setCount, float64(w))
+				b.vertical = true
+
+				if d.drawType == DrawUp {
+					b.base, b.dir = space, -1
+				} else {
+					b.base, b.dir = baseSize, 1
+				}
+
+				d.drawPixelBar(b)
+			}
+		}
+
+		if d.drawType == DrawUp {
+			fillPixels(d.pixels, 0, space, float64(w), float64(h), center)
+		} else {
+			fillPixels(d.pixels, 0, 0, float64(w), baseSize, center)
+		}
+
+	case DrawUpDown, DrawLeftRight:
+		vertical := d.drawType == DrawUpDown
+
+		size, along, baseSize := float64(w), float64(h), float64(d.baseSize*ch)
+		if !vertical {
+			size, along, baseSize = float64(h), float64(w), float64(d.baseSize*cw)
+		}
+
+		centerStart := math.Max((along-baseSize)/2, 0)
+		centerStop := centerStart + baseSize
+		space := math.Min(centerStart, along-centerStop)
+
+		for xBar := 0; xBar < count; xBar++ {
+			xBin := xBar
+			if !vertical {
+				// draw higher frequencies at the top
+				xBin = count - 1 - xBar
+			}
+
+			start, stop := across(xBar, count, size)
+
+			l := bar(0, xBin, bins[0][xBin], space)
+			l.start, l.stop, l.base, l.dir, l.vertical = start, stop, centerStart, -1, vertical
+			d.drawPixelBar(l)
+
+			r := bar(1%setCount, xBin, bins[1%setCount][xBin], space)
+			r.start, r.stop, r.base, r.dir, r.vertical = start, stop, centerStop, 1, vertical
+			d.drawPixelBar(r)
+		}
+
+		if vertical {
+			fillPixels(d.pixels, 0, centerStart, size, centerStop, center)
+		} else {
+			fillPixels(d.pixels, centerStart, 0, centerStop, size, center)
+		}
+	}
+
+	canvas.SetImage(0, d.headerHeight, d.pixels)
+	d.pixelShown = true
+}
+
+// drawPixelBar draws a bar and its peak.
+func (d *Display) drawPixelBar(b pixelBar) {
+	var heightColor = func(dist float64) Color {
+		if d.gradientMode == GradientHeight {
+			return d.gradient.At(dist / math.Max(b.space, 1))
+		}
+
+		return b.color
+	}
+
+	var span = func(from, to float64, color func(dist float64) Color) {
+		lo, hi := b.base+(b.dir*from), b.base+(b.dir*to)
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+
+		for p := math.Floor(lo); p < hi; p++ {
+			coverage := overlap(p, lo, hi)
+			c := color(math.Abs(p + 0.5 - b.base))
+
+			for q := math.Floor(b.start); q < b.stop; q++ {
+				x, y := q, p
+				if !b.vertical {
+					x, y = p, q
+				}
+
+				blendPixel(d.pixels, int(x), int(y), c, coverage*overlap(q, b.start, b.stop))
+			}
+		}
+	}
+
+	span(0, b.length, heightColor)
+
+	if !b.hasPeak {
+		return
+	}
+
+	var peakColor = func(float64) Color {
+		if d.styles.Peak != termbox.ColorDefault {
+			return attributeColor(d.styles.Peak, PixelForeground)
+		}
+
+		return heightColor(b.peak)
+	}
+
+	// a thin cap, a thirty second of the space and at most a quarter of the
+	// bar width thick.
+	var thick = math.Max(math.Min(b.space/32, (b.stop-b.start)/4), 1)
+
+	span(math.Max(b.peak-thick, b.length), b.peak, peakColor)
+}
+
+// overlap returns how much of the pixel at p is within [lo, hi).
+func overlap(p, lo, hi float64) float64 {
+	return math.Max(math.Min(p+1, hi)-math.Max(p, lo), 0)
+}
+
+// fillPixels fills a rectangle, blending the pixels on its edges.
+func fillPixels(img *image.NRGBA, x0, y0, x1, y1 float64, c Color) {
+	for y := math.Floor(y0); y < y1; y++ {
+		for x := math.Floor(x0); x < x1; x++ {
+			blendPixel(img, int(x), int(y), c, overlap(x, x0, x1)*overlap(y, y0, y1))
+		}
+	}
+}
+
+// blendPixel draws c over the pixel at x, y with coverage a.
+func blendPixel(img *image.NRGBA, x, y int, c Color, a float64) {
+	if a <= 0 || !image.Pt(x, y).In(img.Rect) {
+		return
+	}
+
+	a = math.Min(a, 1)
+
+	var i = img.PixOffset(x, y)
+	var pix = img.Pix[i : i+4 : i+4]
+
+	var da = float64(pix[3]) / 255
+	var oa = a + (da * (1 - a))
+
+	var mix = func(src, dst uint8) uint8 {
+		return uint8((((float64(src) * a) + (float64(dst) * da * (1 - a))) / oa) + 0.5)
+	}
+
+	pix[0] = mix(c.R, pix[0])
+	pix[1] = mix(c.G, pix[1])
+	pix[2] = mix(c.B, pix[2])
+	pix[3] = uint8((oa * 255) + 0.5)
+}
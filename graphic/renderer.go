@@ -0,0 +1,59 @@
+package graphic
+
+import (
+	"github.com/nsf/termbox-go"
+)
+
+// Canvas is a grid of cells the display draws on. Colors are termbox
+// attributes, 24-bit ones if the renderer was initialized with truecolor.
+type Canvas interface {
+	// SetCell sets the cell at column x and row y.
+	SetCell(x, y int, r rune, fg, bg termbox.Attribute)
+	// Size returns the number of columns and rows.
+	Size() (int, int)
+	// Clear sets every cell to a space with fg and bg.
+	Clear(fg, bg termbox.Attribute) error
+	// Flush shows the cells set since the last flush.
+	Flush() error
+}
+
+// Renderer is a canvas with an output and input of its own.
+type Renderer interface {
+	Canvas
+	// Init prepares the output.
+	Init(truecolor bool) error
+	// Close restores the output.
+	Close() error
+	// PollEvent blocks until the next event.
+	PollEvent() Event
+	// Interrupt makes PollEvent return an EventInterrupt.
+	Interrupt()
+}
+
+// EventType is the type of an event.
+type EventType int
+
+// event types
+const (
+	EventNone EventType = iota
+	EventKey
+	EventResize
+	EventInterrupt
+	EventError
+)
+
+// Event is an input event of a renderer. Keys are termbox key codes.
+type Event struct {
+	Type   EventType
+	Key    termbox.Key
+	Ch     rune
+	Width  int
+	Height int
+	Err    error
+}
+
+// SetRenderer sets the renderer the display draws with. It must be called
+// before Init, the display renders to the terminal with termbox otherwise.
+func (d *Display) SetRenderer(r Renderer) {
+	d.renderer = r
+}
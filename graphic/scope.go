@@ -0,0 +1,111 @@
+package graphic
+
+import (
+	"math"
+
+	"github.com/nsf/termbox-go"
+)
+
+// SetSamples sets the raw samples of each channel drawn by the draw types
+// that show the waveform, such as DrawScope.
+func (d *Display) SetSamples(samples [][]float64) {
+	d.samples = samples
+}
+
+// trigger returns the index of the first rising zero crossing of samples
+// that leaves span samples after it, or 0 if there is none.
+func trigger(samples []float64, span int) int {
+	for idx := 1; idx+span <= len(samples); idx++ {
+		if samples[idx-1] < 0.0 && samples[idx] >= 0.0 {
+			return idx
+		}
+	}
+
+	return 0
+}
+
+// DrawScope will draw the waveform of the samples. Channels are drawn in
+// lanes of their own, or over each other if overlay is set. Half the samples
+// are drawn, starting at a rising zero crossing of the first channel so
+// periodic waves stand still.
+func (d *Display) DrawScope(overlay bool) {
+	if len(d.samples) == 0 || len(d.samples[0]) < 2 {
+		return
+	}
+
+	width := d.width()
+	if width <= 0 {
+		return
+	}
+
+	span := len(d.samples[0]) / 2
+	start := trigger(d.samples[0], span)
+
+	lanes := len(d.samples)
+	if overlay {
+		lanes = 1
+	}
+
+	laneHeight := d.height() / lanes
+
+	for xSet, samples := range d.samples {
+		lane := xSet
+		if overlay {
+			lane = 0
+		}
+
+		fg := d.styles.Foreground
+		if xSet%2 == 1 {
+			fg = d.styles.CenterLine
+		}
+
+		d.drawWave(samples[start:start+span], lane*laneHeight, laneHeight, width, fg)
+	}
+}
+
+// drawWave draws samples over width columns of the rows from top. Every
+// column shows the range of its samples, at half a row of resolution.
+func (d *Display) drawWave(samples []float64, top, rows, width int, fg termbox.Attribute) {
+	if rows <= 0 {
+		return
+	}
+
+	halves := rows * 2
+
+	// toHalf maps a sample in [-1, 1] to a half row, with 1 at the top.
+	toHalf := func(v float64) int {
+		v = math.Max(math.Min(v, 1.0), -1.0)
+		return intMin(int((1.0-v)/2.0*float64(halves)), halves-1)
+	}
+
+	prev := toHalf(samples[0])
+
+	for xCol := 0; xCol < width; xCol++ {
+		first := (xCol * len(samples)) / width
+		last := intMax(((xCol+1)*len(samples))/width, first+1)
+
+		lo, hi := prev, prev
+		for _, v := range samples[first:intMin(last, len(samples))] {
+			half := toHalf(v)
+			lo, hi = intMin(lo, half), intMax(hi, half)
+		}
+
+		// continue from where the last column ended.
+		prev = toHalf(samples[intMin(last, len(samples))-1])
+
+		for xRow := lo / 2; xRow <= hi/2; xRow++ {
+			upper := xRow*2 >= lo && xRow*2 <= hi
+			lower := (xRow*2)+1 >= lo && (xRow*2)+1 <= hi
+
+			r := BarRune
+			switch {
+			case !lower:
+				r = BarRuneV
+			case !upper:
+				r = BarRuneL
+			}
+
+			d.setCell(xCol, top+xRow, r, fg, d.styles.Background)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package graphic
+
+import (
+	"math"
+
+	"github.com/nsf/termbox-go"
+)
+
+// HeatPalette runs from black through blue, magenta, red and yellow to white
+// in the 6x6x6 color cube of the 256 color mode.
+var HeatPalette = heatPalette()
+
+func heatPalette() []termbox.Attribute {
+	// cube returns the attribute of a color cube color. Attributes are one
+	// above the color index.
+	var cube = func(r, g, b int) termbox.Attribute {
+		return termbox.Attribute(16 + (36 * r) + (6 * g) + b + 1)
+	}
+
+	var palette = []termbox.Attribute{cube(0, 0, 0)}
+
+	for step := 1; step <= 5; step++ {
+		palette = append(palette, cube(0, 0, step))
+	}
+
+	for step := 1; step <= 5; step++ {
+		palette = append(palette, cube(step, 0, 5))
+	}
+
+	for step := 4; step >= 0; step-- {
+		palette = append(palette, cube(5, 0, step))
+	}
+
+	for step := 1; step <= 5; step++ {
+		palette = append(palette, cube(5, step, 0))
+	}
+
+	for step := 1; step <= 5; step++ {
+		palette = append(palette, cube(5, 5, step))
+	}
+
+	return palette
+}
+
+// paletteColor returns the color of value in [0, 1] from palette.
+func paletteColor(palette []termbox.Attribute, value float64) termbox.Attribute {
+	value = math.Max(math.Min(value, 1.0), 0.0)
+	return palette[int(value*float64(len(palette)-1))]
+}
+
+// DrawSpectrogram will draw the history of the bins as a waterfall scrolling
+// from right to left, with low frequencies at the bottom. Every row of cells
+// shows two bins with half blocks. Channels are merged by their loudest bin.
+func (d *Display) DrawSpectrogram(bins [][]float64, count int, scale float64) {
+	if count <= 0 {
+		return
+	}
+
+	if len(d.merged) < count {
+		d.merged = make([]float64, count)
+	}
+
+	var merged = d.merged[:count]
+
+	copy(merged, bins[0][:count])
+	for _, chBins := range bins[1:] {
+		for xBin, v := range chBins[:count] {
+			merged[xBin] = math.Max(merged[xBin], v)
+		}
+	}
+
+	width := d.width()
+
+	d.history.push(merged, count, width, 1.0/scale)
+
+	// rows of cells from the bottom, two bins each.
+	rows := intMin(d.height(), (count+1)/2)
+	bottom := d.height() - 1
+
+	for xCol := 0; xCol < width; xCol++ {
+		age := width - 1 - xCol
+
+		for xRow := 0; xRow < rows; xRow++ {
+			lower, ok := d.history.at(age, xRow*2)
+			if !ok {
+				break
+			}
+
+			upper, _ := d.history.at(age, (xRow*2)+1)
+
+			d.setCell(xCol, bottom-xRow, BarRuneV,
+				paletteColor(HeatPalette, upper),
+				paletteColor(HeatPalette, lower))
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package graphic
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Stereo is the stereo image drawn in the header.
+type Stereo struct {
+	Correlation float64   // correlation in the range [-1, 1]
+	Balance     float64   // balance in the range [-1, 1], -1 is left only
+	MidSide     float64   // mid to side energy ratio in decibels
+	Widths      []float64 // width of each band in the range [0, 1]
+}
+
+// SetStereo sets the stereo image drawn in the two header rows: the
+// correlation bar followed by the mid to side ratio and balance, and the
+// width of each band from low to high. The header is removed if stereo is
+// nil.
+func (d *Display) SetStereo(stereo *Stereo) {
+	d.stereo = stereo
+
+	height := 0
+	if stereo != nil {
+		height = 2
+	}
+
+	if height != d.headerHeight {
+		d.headerHeight = height
+		d.updateStyleBuffer()
+	}
+}
+
+// drawStereo draws the header. sets is the number of channels drawn, which
+// are side by side when bars grow up or down.
+func (d *Display) drawStereo(sets int, pixels bool) {
+	if d.stereo == nil || d.termHeight < d.headerHeight {
+		return
+	}
+
+	const lLabel, rLabel = "-1 ", " +1"
+
+	barWidth := d.termWidth - len(lLabel) - len(rLabel)
+	if barWidth < 3 {
+		return
+	}
+
+	// the values go after the bar if there is room for both.
+	text := fmt.Sprintf(" M/S %+5.1f dB  L/R %+5.2f", d.stereo.MidSide, clampBalance(d.stereo.Balance))
+	if barWidth-len(text) >= 3 {
+		barWidth -= len(text)
+
+		fg := d.styles.Text
+		if fg == termbox.ColorDefault {
+			fg = d.styles.Foreground
+		}
+
+		xCol := len(lLabel) + barWidth + len(rLabel)
+		for _, r := range text {
+			d.screenCell(xCol, 0, r, fg, d.styles.Background)
+			xCol++
+		}
+	}
+
+	for xCol, r := range lLabel {
+		d.screenCell(xCol, 0, r, d.styles.Foreground, d.styles.Background)
+	}
+
+	for xCol, r := range rLabel {
+		d.screenCell(len(lLabel)+barWidth+xCol, 0, r, d.styles.Foreground, d.styles.Background)
+	}
+
+	center := barWidth / 2
+
+	corr := math.Max(math.Min(d.stereo.Correlation, 1.0), -1.0)
+	if math.IsNaN(corr) {
+		corr = 0.0
+	}
+
+	marker := int(math.Round((corr + 1.0) / 2.0 * float64(barWidth-1)))
+
+	lFill, rFill := intMin(center, marker), intMax(center, marker)
+
+	for xCol := 0; xCol < barWidth; xCol++ {
+		var r, fg = '\u2500', d.styles.Foreground
+
+		switch {
+		case xCol == center:
+			r = '\u253C'
+		case xCol >= lFill && xCol <= rFill:
+			r = BarRune
+			if corr < 0.0 {
+				// anti-phase stands out.
+				fg = d.styles.CenterLine
+			}
+		}
+
+		d.screenCell(len(lLabel)+xCol, 0, r, fg, d.styles.Background)
+	}
+
+	d.drawWidths(sets, pixels)
+}
+
+// clampBalance keeps balance in [-1, 1], with NaN as the center.
+func clampBalance(balance float64) float64 {
+	if math.IsNaN(balance) {
+		return 0
+	}
+
+	return math.Max(math.Min(balance, 1.0), -1.0)
+}
+
+// drawWidths draws the width of each band above the columns of its bars.
+// Without bars in columns, the bands are stretched across the width.
+func (d *Display) drawWidths(sets int, pixels bool) {
+	bands := len(d.stereo.Widths)
+	if bands == 0 {
+		return
+	}
+
+	shades := []rune(ShadeRunes)
+	maxShade := float64(len(shades) - 1)
+
+	shade := func(xCol, xBin int) {
+		width := clamp(d.stereo.Widths[xBin])
+		d.screenCell(xCol, 1, shades[int(width*maxShade)], d.styles.Foreground, d.styles.Background)
+	}
+
+	switch {
+	case pixels:
+		sets = 0
+	case d.drawType == DrawUpDown:
+		sets = 1
+	case d.drawType != DrawUp && d.drawType != DrawDown:
+		sets = 0
+	}
+
+	if sets == 0 {
+		for xCol := 0; xCol < d.termWidth; xCol++ {
+			shade(xCol, (xCol*bands)/d.termWidth)
+		}
+
+		return
+	}
+
+	for xSet := 0; xSet < sets; xSet++ {
+		for xBar := 0; xBar < bands; xBar++ {
+			xBin := (xBar * (1 - xSet)) + (((bands - 1) - xBar) * xSet)
+
+			first, last := d.barColumns(xBar, xSet, sets, bands)
+			for xCol := intMax(first, 0); xCol < intMin(last, d.width()); xCol++ {
+				shade(xCol, xBin)
+			}
+		}
+	}
+}
+
+// barColumns returns the columns [first, last) of the bar xBar of set xSet
+// when sets of count bars grow up or down, side by side.
+func (d *Display) barColumns(xBar, xSet, sets, count int) (int, int) {
+	if d.brailleBars() {
+		// braille bars are laid out in dots, two per column.
+		width := d.width() * 2
+
+		paddedWidth := intMax(intMin((d.binSize*count*sets)-d.spaceSize, width), 0)
+		edgeOffset := intMax((width-paddedWidth)/2, 0)
+
+		pos := (xBar * d.binSize) + (d.binSize * count * xSet) + edgeOffset
+
+		return pos / 2, (pos + d.barSize + 1) / 2
+	}
+
+	paddedWidth := intMax(intMin((d.binSize*count*sets)-d.spaceSize, d.width()), 0)
+	edgeOffset := intMax((d.width()-paddedWidth)/2, 0)
+
+	xCol := (xBar * d.binSize) + (d.binSize * count * xSet) + edgeOffset
+	span := d.barSpan(xBar+(count*xSet), count*sets, xCol, xCol+d.barSize)
+
+	return span.first(), span.last()
+}
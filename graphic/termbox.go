@@ -0,0 +1,74 @@
+package graphic
+
+import (
+	"github.com/nsf/termbox-go"
+)
+
+// Termbox renders to the terminal with termbox.
+type Termbox struct{}
+
+// Init initializes termbox.
+func (Termbox) Init(truecolor bool) error {
+	if err := termbox.Init(); err != nil {
+		return err
+	}
+
+	termbox.SetInputMode(termbox.InputAlt)
+	if truecolor {
+		termbox.SetOutputMode(termbox.OutputRGB)
+	} else {
+		termbox.SetOutputMode(termbox.Output256)
+	}
+	termbox.HideCursor()
+
+	return nil
+}
+
+// Close closes termbox and restores the terminal.
+func (Termbox) Close() error {
+	termbox.Close()
+	return nil
+}
+
+// SetCell sets a cell of the back buffer.
+func (Termbox) SetCell(x, y int, r rune, fg, bg termbox.Attribute) {
+	termbox.SetCell(x, y, r, fg, bg)
+}
+
+// Size returns the size of the terminal.
+func (Termbox) Size() (int, int) {
+	return termbox.Size()
+}
+
+// Clear clears the back buffer.
+func (Termbox) Clear(fg, bg termbox.Attribute) error {
+	return termbox.Clear(fg, bg)
+}
+
+// Flush draws the back buffer to the terminal.
+func (Termbox) Flush() error {
+	return termbox.Flush()
+}
+
+// PollEvent waits for the next termbox event.
+func (Termbox) PollEvent() Event {
+	var ev = termbox.PollEvent()
+
+	switch ev.Type {
+	case termbox.EventKey:
+		return Event{Type: EventKey, Key: ev.Key, Ch: ev.Ch}
+	case termbox.EventResize:
+		return Event{Type: EventResize, Width: ev.Width, Height: ev.Height}
+	case termbox.EventInterrupt:
+		return Event{Type: EventInterrupt}
+	case termbox.EventError:
+		return Event{Type: EventError, Err: ev.Err}
+	default:
+		return Event{Type: EventNone}
+	}
+}
+
+// Interrupt interrupts PollEvent.
+func (Termbox) Interrupt() {
+	termbox.Interrupt()
+}
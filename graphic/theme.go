@@ -0,0 +1,287 @@
+package graphic
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nsf/termbox-go"
+	"github.com/pkg/errors"
+)
+
+// ThemeExt is the extension of theme files.
+const ThemeExt = ".json"
+
+// Theme is a named color scheme. Colors, gradient stops included, are parsed
+// by ParseColor: #rrggbb or #rgb, or "0" to "255" for the 256 color mode.
+// Colors other than the stops can also be "default" for the terminal colors,
+// which a gradient has no way to blend.
+//
+// A theme file holds one theme as json:
+//
+//	{
+//		"name": "ocean",
+//		"foreground": "#5fafd7",
+//		"background": "default",
+//		"center": "#005f87",
+//		"peak": "#ffffff",
+//		"text": "#87d7ff",
+//		"gradient": ["#005f87", "#5fafd7", "#afffff"]
+//	}
+type Theme struct {
+	Name       string   `json:"name"`
+	Foreground string   `json:"foreground"`
+	Background string   `json:"background"`
+	CenterLine string   `json:"center"`
+	Peak       string   `json:"peak"`
+	Text       string   `json:"text"`
+	Gradient   []string `json:"gradient"`
+}
+
+// BuiltinThemes are the themes that come with catnip.
+var BuiltinThemes = []Theme{
+	{
+		Name:       "default",
+		Foreground: "default",
+		Background: "default",
+		CenterLine: "5",
+	},
+	{
+		Name:       "nord",
+		Foreground: "#88c0d0",
+		Background: "default",
+		CenterLine: "#5e81ac",
+		Peak:       "#eceff4",
+		Text:       "#d8dee9",
+		Gradient:   []string{"#5e81ac", "#88c0d0", "#8fbcbb", "#a3be8c"},
+	},
+	{
+		Name:       "gruvbox",
+		Foreground: "#fabd2f",
+		Background: "default",
+		CenterLine: "#d65d0e",
+		Peak:       "#fbf1c7",
+		Text:       "#ebdbb2",
+		Gradient:   []string{"#b8bb26", "#fabd2f", "#fe8019", "#fb4934"},
+	},
+	{
+		Name:       "dracula",
+		Foreground: "#bd93f9",
+		Background: "default",
+		CenterLine: "#6272a4",
+		Peak:       "#f8f8f2",
+		Text:       "#f8f8f2",
+		Gradient:   []string{"#8be9fd", "#bd93f9", "#ff79c6"},
+	},
+	{
+		Name:       "fire",
+		Foreground: "#ff8700",
+		Background: "default",
+		CenterLine: "#870000",
+		Peak:       "#ffff87",
+		Gradient:   []string{"#870000", "#ff0000", "#ff8700", "#ffff00"},
+	},
+	{
+		Name:       "mono",
+		Foreground: "#d0d0d0",
+		Background: "default",
+		CenterLine: "#585858",
+		Peak:       "#ffffff",
+		Text:       "#a8a8a8",
+		Gradient:   []string{"#585858", "#d0d0d0"},
+	},
+}
+
+// ThemeDir returns the directory theme files are loaded from.
+func ThemeDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find the config directory")
+	}
+
+	return filepath.Join(dir, "catnip", "themes"), nil
+}
+
+// LoadThemes returns the builtin themes followed by the themes in dir, in
+// name order. A theme file replaces the builtin theme of the same name. The
+// name of a theme defaults to the name of its file. A missing directory is
+// not an error.
+func LoadThemes(dir string) ([]Theme, error) {
+	var themes = append([]Theme(nil), BuiltinThemes...)
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+ThemeExt))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list themes")
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		theme, err := LoadTheme(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if idx := FindTheme(themes, theme.Name); idx >= 0 {
+			themes[idx] = theme
+			continue
+		}
+
+		themes = append(themes, theme)
+	}
+
+	return themes, nil
+}
+
+// LoadTheme reads the theme file at path.
+func LoadTheme(path string) (Theme, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Theme{}, errors.Wrap(err, "failed to read theme")
+	}
+
+	var theme Theme
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return Theme{}, errors.Wrapf(err, "failed to parse theme %q", path)
+	}
+
+	if theme.Name == "" {
+		theme.Name = strings.TrimSuffix(filepath.Base(path), ThemeExt)
+	}
+
+	if err := theme.Validate(); err != nil {
+		return Theme{}, errors.Wrapf(err, "invalid theme %q", path)
+	}
+
+	return theme, nil
+}
+
+// FindTheme returns the index of the theme called name, or -1.
+func FindTheme(themes []Theme, name string) int {
+	for idx := range themes {
+		if strings.EqualFold(themes[idx].Name, name) {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// Validate checks every color of the theme.
+func (t Theme) Validate() error {
+	for _, color := range []string{t.Foreground, t.Background, t.CenterLine, t.Peak, t.Text} {
+		if _, err := parseThemeColor(color); err != nil {
+			return err
+		}
+	}
+
+	_, err := ParseGradient(t.Gradient)
+
+	return err
+}
+
+// themeColor is a color of a theme. Index is the color of the 256 color mode
+// plus one, zero for the default color, if RGB is false.
+type themeColor struct {
+	Color
+	index int
+	rgb   bool
+}
+
+func parseThemeColor(s string) (themeColor, error) {
+	switch s = strings.TrimSpace(s); {
+	case s == "" || strings.EqualFold(s, "default"):
+		return themeColor{}, nil
+
+	case strings.HasPrefix(s, "#"):
+		color, err := ParseColor(s)
+		return themeColor{Color: color, rgb: true}, err
+	}
+
+	if _, err := ParseColor(s); err != nil {
+		return themeColor{}, err
+	}
+
+	// keep the index, so the terminal palette is used as is.
+	idx, _ := strconv.Atoi(s)
+
+	return themeColor{index: idx + 1}, nil
+}
+
+// themeAttribute returns the attribute of a theme color. Colors were checked
+// when the theme was loaded.
+func (d *Display) themeAttribute(s string) termbox.Attribute {
+	color, _ := parseThemeColor(s)
+	if color.rgb {
+		return d.color(color.Color)
+	}
+
+	return termbox.Attribute(color.index)
+}
+
+// SetThemes sets the themes cycled through with 't'.
+func (d *Display) SetThemes(themes []Theme) {
+	d.themes = themes
+	d.theme = -1
+}
+
+// SetTheme applies the theme at idx of the themes.
+func (d *Display) SetTheme(idx int) {
+	if len(d.themes) == 0 {
+		return
+	}
+
+	idx %= len(d.themes)
+	if idx < 0 {
+		idx += len(d.themes)
+	}
+
+	d.theme = idx
+	theme := d.themes[idx]
+
+	// themes without a gradient go back to the one given by flags.
+	d.gradient = d.flagGradient
+	if len(theme.Gradient) > 0 {
+		// checked when the theme was loaded.
+		d.gradient, _ = ParseGradient(theme.Gradient)
+	}
+
+	if len(d.gradient) == 0 {
+		d.gradient = DefaultGradient
+	}
+
+	d.barColors = d.barColors[:0]
+
+	d.SetStyles(Styles{
+		Foreground: d.themeAttribute(theme.Foreground),
+		Background: d.themeAttribute(theme.Background),
+		CenterLine: d.themeAttribute(theme.CenterLine),
+		Peak:       d.themeAttribute(theme.Peak),
+		Text:       d.themeAttribute(theme.Text),
+	})
+}
+
+// CycleTheme applies the theme step themes away from the current one. With no
+// theme applied, stepping forward starts at the first theme and stepping back
+// at the last.
+func (d *Display) CycleTheme(step int) {
+	var idx = d.theme + step
+	if d.theme < 0 && step < 0 {
+		idx = len(d.themes) + step
+	}
+
+	d.SetTheme(idx)
+}
+
+// Theme returns the name of the current theme, empty if none was applied.
+func (d *Display) Theme() string {
+	if d.theme < 0 || d.theme >= len(d.themes) {
+		return ""
+	}
+
+	return d.themes[d.theme].Name
+}
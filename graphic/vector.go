@@ -0,0 +1,53 @@
+package graphic
+
+import (
+	"math"
+)
+
+const (
+	// VectorFade is how much of its intensity a point keeps every frame.
+	VectorFade = 0.8
+	// VectorThreshold is the intensity below which points are not drawn.
+	VectorThreshold = 0.1
+)
+
+// DrawVector will plot the left channel of the samples against the right on
+// a square of braille dots. With midSide, the plot is rotated 45 degrees so
+// mono sits on the vertical axis and anti-phase on the horizontal, as on a
+// goniometer. Otherwise left is x and right is y. Points fade out over a
+// few frames.
+func (d *Display) DrawVector(midSide bool) {
+	if len(d.samples) == 0 {
+		return
+	}
+
+	// dots are about square, and there are 4 of them per row and 2 per column.
+	// size is even, but may leave a partly used row at the bottom.
+	size := intMin(d.width()*2, d.height()*4)
+	cols, rows := size/2, (size+3)/4
+
+	d.vector.resize(cols, rows)
+	d.vector.fade(VectorFade)
+
+	left, right := d.samples[0], d.samples[len(d.samples)-1]
+
+	half := float64(size-1) / 2.0
+
+	for idx := range left {
+		x, y := left[idx], right[idx]
+
+		if midSide {
+			x, y = (right[idx]-left[idx])/math.Sqrt2, (left[idx]+right[idx])/math.Sqrt2
+		}
+
+		x = math.Max(math.Min(x, 1.0), -1.0)
+		y = math.Max(math.Min(y, 1.0), -1.0)
+
+		d.vector.plot(int(math.Round((x+1.0)*half)), int(math.Round((1.0-y)*half)))
+	}
+
+	xOffset := (d.width() - cols) / 2
+	yOffset := (d.height() - rows) / 2
+
+	d.drawBraille(&d.vector, xOffset, yOffset, VectorThreshold, GreyPalette)
+}
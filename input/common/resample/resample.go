@@ -0,0 +1,128 @@
+// Package resample provides a windowed sinc resampler for sample buffers and
+// a session that resamples the buffers of another session.
+package resample
+
+import (
+	"math"
+
+	"github.com/noriah/catnip/input"
+)
+
+// Zeros is the number of zero crossings of the sinc on each side of a
+// sample. More zeros give a sharper cutoff at the cost of more work.
+const Zeros = 16
+
+// Resampler converts consecutive buffers of one size into buffers of another
+// size covering the same time, changing the sample rate by the ratio of the
+// sizes.
+//
+// Buffers are treated as a stream. The output lags the input by a few
+// samples so every output sample sees the full kernel.
+type Resampler struct {
+	In  int // samples per input buffer
+	Out int // samples per output buffer
+
+	half    int         // kernel taps on each side of a sample
+	starts  []int       // first tap of each output sample
+	weights [][]float64 // weights of each output sample
+
+	ext [][]float64 // history followed by the input, for each channel
+}
+
+// New returns a resampler for channels channels from in samples to out
+// samples per buffer.
+func New(channels, in, out int) *Resampler {
+	var ratio = float64(in) / float64(out)
+
+	// when going down, filter at the output nyquist instead.
+	var cutoff = math.Min(1.0, 1.0/ratio)
+
+	var r = &Resampler{
+		In:      in,
+		Out:     out,
+		half:    int(math.Ceil(Zeros / cutoff)),
+		starts:  make([]int, out),
+		weights: make([][]float64, out),
+		ext:     make([][]float64, channels),
+	}
+
+	var history = r.history()
+
+	for idx := range r.ext {
+		r.ext[idx] = make([]float64, history+in)
+	}
+
+	var taps = make([]float64, out*2*r.half)
+
+	for idx := range r.weights {
+		center := (float64(idx) * ratio) + float64(r.half) + 1.0
+		start := int(math.Floor(center)) - r.half + 1
+
+		weights := taps[:2*r.half]
+		taps = taps[2*r.half:]
+
+		var sum float64
+		for tap := range weights {
+			x := float64(start+tap) - center
+			weights[tap] = cutoff * sinc(cutoff*x) * blackman(x/float64(r.half+1))
+			sum += weights[tap]
+		}
+
+		// unity gain at dc.
+		for tap := range weights {
+			weights[tap] /= sum
+		}
+
+		r.starts[idx] = start
+		r.weights[idx] = weights
+	}
+
+	return r
+}
+
+// history returns the number of samples kept from the previous buffer.
+func (r *Resampler) history() int {
+	return (2 * r.half) + 2
+}
+
+// Delay returns how many input samples the output lags the input by.
+func (r *Resampler) Delay() int {
+	return r.half + 1
+}
+
+// Process resamples the buffers of src into dst. Every call moves the stream
+// along, so src must be the next buffers of the input.
+func (r *Resampler) Process(dst, src [][]input.Sample) {
+	var history = r.history()
+
+	for ch, buf := range src {
+		ext := r.ext[ch]
+
+		copy(ext[history:], buf)
+
+		out := dst[ch]
+		for idx, weights := range r.weights {
+			var v float64
+			for tap, w := range weights {
+				v += ext[r.starts[idx]+tap] * w
+			}
+			out[idx] = v
+		}
+
+		// keep the end of this buffer for the next one.
+		copy(ext[:history], ext[r.In:])
+	}
+}
+
+func sinc(x float64) float64 {
+	if x == 0.0 {
+		return 1.0
+	}
+
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman is a blackman window over [-1, 1].
+func blackman(x float64) float64 {
+	return 0.42 + (0.5 * math.Cos(math.Pi*x)) + (0.08 * math.Cos(2.0*math.Pi*x))
+}
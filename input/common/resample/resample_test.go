@@ -0,0 +1,101 @@
+package resample
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResampler(t *testing.T) {
+	var tests = []struct {
+		in, out int
+		freq    float64 // in cycles per input sample
+	}{
+		{1024, 1024, 0.01},
+		{480, 441, 0.02},
+		{441, 480, 0.02},
+		{400, 1024, 0.05},
+		{1024, 400, 0.05},
+	}
+
+	for _, test := range tests {
+		var r = New(1, test.in, test.out)
+		var ratio = float64(test.in) / float64(test.out)
+
+		var src = [][]float64{make([]float64, test.in)}
+		var dst = [][]float64{make([]float64, test.out)}
+
+		var worst float64
+
+		for block := 0; block < 8; block++ {
+			for idx := range src[0] {
+				n := float64((block * test.in) + idx)
+				src[0][idx] = math.Sin(2.0 * math.Pi * test.freq * n)
+			}
+
+			r.Process(dst, src)
+
+			// let the history fill up.
+			if block < 2 {
+				continue
+			}
+
+			for idx, v := range dst[0] {
+				n := float64(block*test.in) + (float64(idx) * ratio) - float64(r.Delay())
+				worst = math.Max(worst, math.Abs(v-math.Sin(2.0*math.Pi*test.freq*n)))
+			}
+		}
+
+		if worst > 1e-3 {
+			t.Errorf("%d to %d: error %v", test.in, test.out, worst)
+		}
+	}
+}
+
+// counter counts the calls of a processor.
+type counter struct {
+	fresh, stale int
+}
+
+func (c *counter) Process(fresh bool) {
+	if fresh {
+		c.fresh++
+	} else {
+		c.stale++
+	}
+}
+
+func TestProcessorStale(t *testing.T) {
+	var c counter
+	var src = [][]float64{make([]float64, 480)}
+	var dst = [][]float64{make([]float64, 441)}
+	var want = [][]float64{make([]float64, 441)}
+
+	var p = processor{resampler: New(1, 480, 441), dst: dst, src: src, proc: &c}
+	var r = New(1, 480, 441)
+
+	// silence is fresh too, it must move the stream along.
+	for block := 0; block < 4; block++ {
+		for idx := range src[0] {
+			src[0][idx] = 0
+			if block%2 == 1 {
+				src[0][idx] = math.Sin(float64(idx) / 10.0)
+			}
+		}
+
+		r.Process(want, src)
+
+		p.Process(true)
+		p.Process(false)
+		p.Process(false)
+
+		for idx := range want[0] {
+			if dst[0][idx] != want[0][idx] {
+				t.Fatalf("block %d sample %d: got %v, want %v", block, idx, dst[0][idx], want[0][idx])
+			}
+		}
+	}
+
+	if c.fresh != 4 || c.stale != 8 {
+		t.Errorf("got %d fresh and %d stale calls, want 4 and 8", c.fresh, c.stale)
+	}
+}
@@ -0,0 +1,70 @@
+package resample
+
+import (
+	"context"
+	"math"
+
+	"github.com/noriah/catnip/input"
+	"github.com/pkg/errors"
+)
+
+// Session wraps a session running at another rate and resamples its buffers
+// into the ones the processor reads.
+type Session struct {
+	session   input.Session
+	cfg       input.SessionConfig
+	resampler *Resampler
+	buffers   [][]input.Sample
+}
+
+// DeviceConfig returns cfg changed to run at rate, with the sample size
+// changed to cover the same time. The sizes are rounded, so the rate the
+// buffers are resampled to can be off by a fraction of a percent.
+func DeviceConfig(cfg input.SessionConfig, rate float64) input.SessionConfig {
+	cfg.SampleSize = int(math.Round(float64(cfg.SampleSize) * rate / cfg.SampleRate))
+	cfg.SampleRate = rate
+	return cfg
+}
+
+// NewSession wraps session, started with the device config, so it delivers
+// buffers of cfg. The device config must come from DeviceConfig.
+func NewSession(session input.Session, device, cfg input.SessionConfig) *Session {
+	return &Session{
+		session:   session,
+		cfg:       cfg,
+		resampler: New(cfg.FrameSize, device.SampleSize, cfg.SampleSize),
+		buffers:   input.MakeBuffers(device),
+	}
+}
+
+// Start implements input.Session.
+func (s *Session) Start(ctx context.Context, dst [][]input.Sample, proc input.Processor) error {
+	if !input.EnsureBufferLen(s.cfg, dst) {
+		return errors.New("invalid dst length given")
+	}
+
+	return s.session.Start(ctx, s.buffers, processor{
+		resampler: s.resampler,
+		dst:       dst,
+		src:       s.buffers,
+		proc:      proc,
+	})
+}
+
+// processor resamples the device buffers before calling the wrapped
+// processor.
+type processor struct {
+	resampler *Resampler
+	dst, src  [][]input.Sample
+	proc      input.Processor
+}
+
+// Process resamples only fresh buffers. Otherwise dst still holds the last
+// ones.
+func (p processor) Process(fresh bool) {
+	if fresh {
+		p.resampler.Process(p.dst, p.src)
+	}
+
+	p.proc.Process(fresh)
+}
@@ -24,7 +24,8 @@ type Routine func(mu *sync.Mutex) error
 // event or error occurs. The error returned from the callback will be returned
 // from this function; returning an io.EOF will make this function return nil.
 //
-// Processor is called on each tick.
+// Processor is called on each tick, told if the routine returned since the
+// last one.
 func Process(cfg input.SessionConfig, proc input.Processor, r Routine) error {
 	// Calculate the theoretical tick duration to satisfy the requested sampling
 	// rate without falling behind.
@@ -42,9 +43,20 @@ func Process(cfg input.SessionConfig, proc input.Processor, r Routine) error {
 	// parallel, so proc will be accessing it from another thread.
 	var mutex sync.Mutex
 
+	// fresh is set when the routine has written the shared buffer and cleared
+	// when proc sees it. It is guarded by mutex.
+	var fresh bool
+
 	go func() {
 		for {
 			err := r(&mutex)
+
+			if err == nil {
+				mutex.Lock()
+				fresh = true
+				mutex.Unlock()
+			}
+
 			errorCh <- err
 
 			// Bail on error.
@@ -73,7 +85,8 @@ func Process(cfg input.SessionConfig, proc input.Processor, r Routine) error {
 		}
 
 		mutex.Lock()
-		proc.Process()
+		proc.Process(fresh)
+		fresh = false
 		mutex.Unlock()
 	}
 }
@@ -64,8 +64,12 @@ type Session interface {
 // this on another goroutine; the implementation must handle synchronization. It
 // must also handle buffer swapping or copying if it wants to synchronize it
 // away.
+//
+// Sessions may also call it to keep a steady frame rate when no new samples
+// have arrived. fresh is true only if the buffers were written since the last
+// call.
 type Processor interface {
-	Process()
+	Process(fresh bool)
 }
 
 type Sample = float64
@@ -67,14 +67,61 @@ func doFlags(cfg *Config) bool {
 	parser.String(&cfg.Backend, "b", "backend", "backend name")
 	parser.String(&cfg.Device, "d", "device", "device name")
 	parser.Float64(&cfg.SampleRate, "r", "rate", "sample rate")
-	parser.Int(&cfg.SampleSize, "n", "samples", "sample size")
+	parser.Float64(&cfg.DeviceRate, "dr", "device-rate",
+		"rate to read the device at, resampled to the sample rate (0 reads at the sample rate)")
+	parser.Int(&cfg.SampleSize, "n", "samples",
+		"sample size, the fft grows with '.' and shrinks with ','")
 	parser.Int(&cfg.ChannelCount, "ch", "channels", "channel count (1 or 2)")
 	parser.Float64(&cfg.SmoothFactor, "sf", "smoothing", "smooth factor (0-100)")
 	parser.Float64(&cfg.WinVar, "wv", "win", "a0 applied to the window function")
 	parser.Int(&cfg.BaseSize, "bt", "base", "base thickness [0, +Inf)")
 	parser.Int(&cfg.BarSize, "bw", "bar", "bar width [1, +Inf)")
 	parser.Int(&cfg.SpaceSize, "sw", "space", "space width [0, +Inf)")
-	parser.Int(&cfg.DrawType, "dt", "draw", "draw type (1, 2, 3)")
+	parser.Int(&cfg.DrawType, "dt", "draw", "draw type (1..11)")
+	parser.Bool(&cfg.Braille, "br", "braille",
+		"draw bars with braille dots, sizes are in dots, toggle with 'b'")
+	parser.Bool(&cfg.Fill, "fl", "fill",
+		"stretch bars to fill the whole width with sub cell widths")
+	parser.Int(&cfg.Gradient, "gr", "gradient",
+		"bar gradient (0 off, 1 height, 2 frequency, 3 channel)")
+	parser.StringSlice(&cfg.GradientStops, "gs", "gradient-stop",
+		"gradient stop as #rrggbb, #rgb or 0-255, repeatable (default #00ff00, #ffff00, #ff0000)")
+	parser.Bool(&cfg.TrueColor, "tc", "truecolor",
+		"draw 24-bit colors even if $COLORTERM does not say they are supported")
+	parser.Int(&cfg.Pixels, "px", "pixels",
+		"draw bars in pixels with a terminal graphics protocol (0 off, 1 kitty, 2 sixel)")
+	parser.Int(&cfg.Status, "so", "status",
+		"write a status line per frame to stdout instead of drawing (0 off, 1 plain, 2 waybar, 3 i3bar, 4 tmux)")
+	parser.Int(&cfg.Stream, "ss", "stream",
+		"write bar values per frame to stdout instead of drawing (0 off, 1 json lines, 2 csv, 3 binary)")
+	parser.String(&cfg.Web, "ws", "web",
+		"serve a browser visualizer on the address instead of drawing, :8080 listens on all interfaces and 127.0.0.1:8080 on this machine only")
+	parser.Bool(&cfg.Waveform, "wf", "waveform", "send the waveform to the web visualizer")
+	parser.Int(&cfg.OutputBars, "ob", "output-bars",
+		"number of bars per channel of the status line, stream and web visualizer")
+	parser.Int(&cfg.OutputBars, "sn", "status-bars", "same as --output-bars")
+	parser.Float64(&cfg.FrameRate, "fr", "frame-rate",
+		"most frames written to an output a second (0 for the default of the output)")
+	parser.String(&cfg.Theme, "th", "theme", "theme name, 't' and 'T' cycle themes")
+	parser.String(&cfg.ThemeDir, "td", "theme-dir", "directory of json theme files")
+	parser.Bool(&cfg.Meter, "m", "meter", "draw level and loudness meters")
+	parser.Bool(&cfg.Stereo, "st", "stereo", "draw stereo correlation and width")
+	parser.Int(&cfg.NoiseMode, "nm", "noise",
+		"noise floor removal (0 off, 1 subtract, 2 gate), 'n' learns noise, 'N' forgets it")
+	parser.Float64(&cfg.NoiseFactor, "nf", "noise-factor", "factor applied to the noise floor")
+	parser.Int(&cfg.Scaler, "sc", "scaler",
+		"bar scaling (0 window, 1 agc, 2 manual, 3 per band)")
+	parser.Float64(&cfg.Gain, "g", "gain", "manual scaler gain in dB, adjust with '+' and '-'")
+	parser.StringSlice(&cfg.Filters, "f", "filter",
+		"input filter, repeatable (hp:freq[:q], lp:freq[:q], bp:freq[:q], ls:freq:dB, hs:freq:dB, pe[:coef])")
+	parser.Int(&cfg.FFT, "fk", "fft",
+		"fft implementation (0 auto, 1 fftw, 2 gonum, 3 split radix, 4 split radix float32)")
+	parser.Int(&cfg.Planner, "fp", "planner",
+		"fftw planner (0 estimate, 1 measure, 2 patient), plans are cached as wisdom")
+	parser.Int(&cfg.FFTThreads, "ft", "fft-threads", "number of threads fftw runs on, needs a build with -tags fftwthreads")
+	parser.String(&cfg.Stages, "ps", "pipeline", "comma separated order of dsp stages")
+	parser.Bool(&cfg.Peaks, "pk", "peaks", "track and draw bar peaks")
+	parser.Float64(&cfg.Tuning, "tu", "tuning", "frequency of A4 in Hz for pitch classes")
 
 	fg, bg, center := graphic.DefaultStyles().AsUInt16s()
 	parser.UInt16(&fg, "fg", "foreground",
@@ -0,0 +1,109 @@
+// Package output writes the analysis of each frame somewhere other than the
+// terminal display.
+package output
+
+import (
+	"time"
+
+	"github.com/noriah/catnip/dsp/meter"
+)
+
+// Output receives every frame instead of the display.
+type Output interface {
+	// Bars returns the number of bars per channel the output wants.
+	Bars(channels int) int
+	// Write writes a frame. An error stops catnip.
+	Write(f *Frame) error
+	// Close flushes and closes the output.
+	Close() error
+}
+
+// Frame is the analysis of a frame.
+type Frame struct {
+	// Time is when the frame was processed.
+	Time time.Time
+	// Bars are the bars of each channel after smoothing and scaling.
+	Bars [][]float64
+	// Count is the number of bars of each channel.
+	Count int
+	// Scale is the value of a full bar.
+	Scale float64
+	// Peaks are the peaks of each bar, nil if peaks are not tracked.
+	Peaks [][]float64
+	// Ranges are the frequency range of each bar.
+	Ranges []Range
+	// Meter holds the levels and loudness, nil without meters.
+	Meter *meter.Meter
+	// Stereo is the stereo image, nil without it.
+	Stereo *meter.Stereo
+	// Widths are the stereo width of each bar, nil without the stereo image.
+	Widths []float64
+	// Samples are the raw samples of each channel, before any filtering.
+	Samples [][]float64
+}
+
+// Range is a range of frequencies [Low, High) in Hz.
+type Range struct {
+	Low  float64
+	High float64
+}
+
+// Level returns the bar at idx of channel ch in [0, 1]. NaN is 0.
+func (f *Frame) Level(ch, idx int) float64 {
+	var v = f.Bars[ch][idx] / f.Scale
+
+	switch {
+	case v > 1:
+		return 1
+	case v > 0:
+		return v
+	default:
+		return 0
+	}
+}
+
+// Range returns the frequency range of bar idx, zero if it is unknown.
+func (f *Frame) Range(idx int) Range {
+	if idx < len(f.Ranges) {
+		return f.Ranges[idx]
+	}
+
+	return Range{}
+}
+
+// Merged returns the level of bar idx of the loudest channel.
+func (f *Frame) Merged(idx int) float64 {
+	var v float64
+	for ch := range f.Bars {
+		if l := f.Level(ch, idx); l > v {
+			v = l
+		}
+	}
+
+	return v
+}
+
+// limiter lets frames through at most at a rate.
+type limiter struct {
+	interval time.Duration
+	last     time.Time
+}
+
+func newLimiter(rate float64) limiter {
+	if rate <= 0 {
+		return limiter{}
+	}
+
+	return limiter{interval: time.Duration(float64(time.Second) / rate)}
+}
+
+// allow returns true if the frame at now is let through.
+func (l *limiter) allow(now time.Time) bool {
+	if l.interval > 0 && !l.last.IsZero() && now.Sub(l.last) < l.interval {
+		return false
+	}
+
+	l.last = now
+
+	return true
+}
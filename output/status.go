@@ -0,0 +1,139 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/noriah/catnip/graphic"
+)
+
+// StatusRunes are the runes of a status line, from low to high.
+const StatusRunes = "▁▂▃▄▅▆▇█"
+
+// DefaultStatusRate is the frame rate of the status line if none is given.
+const DefaultStatusRate = 20.0
+
+// StatusFormat is the format of each status line.
+type StatusFormat int
+
+// status formats
+const (
+	StatusNone StatusFormat = iota
+	// StatusPlain writes the bars as text.
+	StatusPlain
+	// StatusWaybar writes a waybar custom module json object.
+	StatusWaybar
+	// StatusI3bar writes the i3bar protocol, for i3bar and swaybar.
+	StatusI3bar
+	// StatusTmux writes the bars with tmux color markup.
+	StatusTmux
+	StatusMax
+)
+
+// Status writes a line of bars per frame, for status bars.
+type Status struct {
+	Format   StatusFormat
+	Count    int
+	Gradient graphic.Gradient
+
+	w       *bufio.Writer
+	limit   limiter
+	started bool
+	runes   []rune
+	line    []byte
+}
+
+// NewStatus returns a status output writing count bars to w in format at
+// most rate times a second.
+func NewStatus(w io.Writer, format StatusFormat, count int, rate float64) *Status {
+	if rate <= 0 {
+		rate = DefaultStatusRate
+	}
+
+	return &Status{
+		Format:   format,
+		Count:    count,
+		Gradient: graphic.DefaultGradient,
+		w:        bufio.NewWriter(w),
+		limit:    newLimiter(rate),
+		runes:    []rune(StatusRunes),
+	}
+}
+
+// Bars returns the bar count of the status line.
+func (s *Status) Bars(channels int) int {
+	return s.Count
+}
+
+// Write writes the line of a frame, unless the last line was too recent.
+func (s *Status) Write(f *Frame) error {
+	if !s.limit.allow(f.Time) {
+		return nil
+	}
+
+	if !s.started {
+		s.started = true
+
+		if s.Format == StatusI3bar {
+			// an endless array of status lines follows the header.
+			s.w.WriteString("{\"version\":1}\n[\n")
+		}
+	}
+
+	s.line = s.line[:0]
+
+	for idx := 0; idx < f.Count; idx++ {
+		var level = f.Merged(idx)
+		var r = s.runes[int(level*float64(len(s.runes)-1)+0.5)]
+
+		if s.Format == StatusTmux {
+			s.line = append(s.line, "#[fg="...)
+			s.line = append(s.line, s.Gradient.At(level).String()...)
+			s.line = append(s.line, ']')
+		}
+
+		s.line = append(s.line, string(r)...)
+	}
+
+	switch s.Format {
+	case StatusWaybar:
+		data, err := json.Marshal(struct {
+			Text  string `json:"text"`
+			Class string `json:"class"`
+		}{string(s.line), "catnip"})
+		if err != nil {
+			return err
+		}
+
+		s.w.Write(data)
+
+	case StatusI3bar:
+		data, err := json.Marshal([]struct {
+			Name     string `json:"name"`
+			FullText string `json:"full_text"`
+		}{{"catnip", string(s.line)}})
+		if err != nil {
+			return err
+		}
+
+		s.w.Write(data)
+		s.w.WriteByte(',')
+
+	case StatusTmux:
+		s.w.Write(s.line)
+		s.w.WriteString("#[default]")
+
+	default:
+		s.w.Write(s.line)
+	}
+
+	s.w.WriteByte('\n')
+
+	return s.w.Flush()
+}
+
+// Close flushes the last line.
+func (s *Status) Close() error {
+	return s.w.Flush()
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/noriah/catnip/graphic"
+)
+
+func testFrame(at time.Duration) *Frame {
+	return &Frame{
+		Time: time.Unix(0, 0).Add(at),
+		Bars: [][]float64{
+			{0, 0.5, 2, math.NaN()},
+			{0, 1.0, -1, 0.25},
+		},
+		Count: 4,
+		Scale: 2,
+	}
+}
+
+func TestStatus(t *testing.T) {
+	var tests = []struct {
+		format StatusFormat
+		want   string
+	}{
+		{StatusPlain, "▁▅█▂\n"},
+		{StatusWaybar, "{\"text\":\"▁▅█▂\",\"class\":\"catnip\"}\n"},
+		{StatusI3bar, "{\"version\":1}\n[\n[{\"name\":\"catnip\",\"full_text\":\"▁▅█▂\"}],\n"},
+		{StatusTmux, "#[fg=#000000]▁#[fg=#808080]▅#[fg=#ffffff]█#[fg=#202020]▂#[default]\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		var s = NewStatus(&buf, tt.format, 4, 0)
+		s.Gradient = graphic.Gradient{{R: 0, G: 0, B: 0}, {R: 255, G: 255, B: 255}}
+
+		if err := s.Write(testFrame(0)); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); got != tt.want {
+			t.Errorf("format %d got %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestStatusRate(t *testing.T) {
+	var buf bytes.Buffer
+	var s = NewStatus(&buf, StatusPlain, 4, 10)
+
+	for _, at := range []time.Duration{0, 50, 99, 100, 150, 250} {
+		if err := s.Write(testFrame(at * time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// frames at 0, 100 and 250 ms.
+	if got := bytes.Count(buf.Bytes(), []byte("\n")); got != 3 {
+		t.Errorf("got %d lines, want 3", got)
+	}
+}
@@ -0,0 +1,358 @@
+package output
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/noriah/catnip/dsp/meter"
+)
+
+// StreamFormat is the format of a spectrum stream.
+type StreamFormat int
+
+// stream formats
+const (
+	StreamNone StreamFormat = iota
+	// StreamJSON writes a json object per frame, a line each.
+	StreamJSON
+	// StreamCSV writes a row per bar of each channel, after a header.
+	StreamCSV
+	// StreamBinary writes a little endian record per frame.
+	StreamBinary
+	StreamMax
+)
+
+// binary record flags, set if the part follows.
+const (
+	BinaryPeaks = 1 << iota
+	BinaryMeter
+	BinaryStereo
+)
+
+// Stream writes the bars of each frame with their frequencies, in a format
+// other programs can read. Bars are in [0, 1].
+//
+// A binary record is, all little endian:
+//
+//	uint32  size of the rest of the record in bytes
+//	int64   time in unix nanoseconds
+//	uint16  channels
+//	uint16  bars
+//	uint8   flags
+//	float32 low and high frequency of each bar
+//	float32 bars of each channel
+//	float32 peaks of each channel, if BinaryPeaks
+//	float32 rms, peak and true peak of each channel in dBFS, momentary,
+//	        short-term and integrated loudness in LUFS and the max true
+//	        peak in dBFS, if BinaryMeter
+//	float32 correlation, balance, mid to side ratio in dB and the width of
+//	        each bar, if BinaryStereo
+type Stream struct {
+	Format StreamFormat
+	Count  int
+
+	w       *bufio.Writer
+	limit   limiter
+	started bool
+	buf     []byte
+	record  jsonFrame
+}
+
+// NewStream returns a stream writing count bars per channel to w in format
+// at most rate times a second, every frame if rate is 0.
+func NewStream(w io.Writer, format StreamFormat, count int, rate float64) *Stream {
+	return &Stream{
+		Format: format,
+		Count:  count,
+		w:      bufio.NewWriter(w),
+		limit:  newLimiter(rate),
+	}
+}
+
+// Bars returns the bar count of the stream.
+func (s *Stream) Bars(channels int) int {
+	return s.Count
+}
+
+// Write writes a frame, unless the last frame was too recent.
+func (s *Stream) Write(f *Frame) error {
+	if !s.limit.allow(f.Time) {
+		return nil
+	}
+
+	var err error
+
+	switch s.Format {
+	case StreamJSON:
+		err = s.writeJSON(f)
+	case StreamCSV:
+		err = s.writeCSV(f)
+	case StreamBinary:
+		err = s.writeBinary(f)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return s.w.Flush()
+}
+
+// Close flushes the last frame.
+func (s *Stream) Close() error {
+	return s.w.Flush()
+}
+
+// number is a float that is null in json if it is not finite, as a level of
+// silence is -Inf dB.
+type number float64
+
+func (n number) MarshalJSON() ([]byte, error) {
+	if math.IsInf(float64(n), 0) || math.IsNaN(float64(n)) {
+		return []byte("null"), nil
+	}
+
+	return strconv.AppendFloat(nil, float64(n), 'g', 6, 64), nil
+}
+
+type jsonChannel struct {
+	Channel int      `json:"channel"`
+	Bars    []number `json:"bars"`
+	Peaks   []number `json:"peaks,omitempty"`
+}
+
+type jsonLevels struct {
+	RMS      number `json:"rms"`
+	Peak     number `json:"peak"`
+	TruePeak number `json:"true_peak"`
+}
+
+type jsonMeter struct {
+	Levels      []jsonLevels `json:"levels"`
+	Momentary   number       `json:"momentary"`
+	ShortTerm   number       `json:"short_term"`
+	Integrated  number       `json:"integrated"`
+	MaxTruePeak number       `json:"max_true_peak"`
+}
+
+type jsonStereo struct {
+	Correlation number   `json:"correlation"`
+	Balance     number   `json:"balance"`
+	MidSide     number   `json:"mid_side"`
+	Widths      []number `json:"widths,omitempty"`
+}
+
+type jsonFrame struct {
+	Time     float64       `json:"time"`
+	Ranges   [][2]number   `json:"ranges"`
+	Channels []jsonChannel `json:"channels"`
+	Meter    *jsonMeter    `json:"meter,omitempty"`
+	Stereo   *jsonStereo   `json:"stereo,omitempty"`
+}
+
+// seconds returns the time of the frame in seconds since the unix epoch.
+func seconds(f *Frame) float64 {
+	return float64(f.Time.UnixNano()) / 1e9
+}
+
+func (s *Stream) writeJSON(f *Frame) error {
+	var r = &s.record
+
+	r.Time = seconds(f)
+
+	r.Ranges = r.Ranges[:0]
+	for idx := 0; idx < f.Count; idx++ {
+		var rng = f.Range(idx)
+		r.Ranges = append(r.Ranges, [2]number{number(rng.Low), number(rng.High)})
+	}
+
+	if len(r.Channels) != len(f.Bars) {
+		r.Channels = make([]jsonChannel, len(f.Bars))
+	}
+
+	for ch := range f.Bars {
+		var c = &r.Channels[ch]
+
+		c.Channel = ch
+
+		c.Bars = c.Bars[:0]
+		for idx := 0; idx < f.Count; idx++ {
+			c.Bars = append(c.Bars, number(f.Level(ch, idx)))
+		}
+
+		c.Peaks = c.Peaks[:0]
+		if ch < len(f.Peaks) {
+			for idx := 0; idx < f.Count; idx++ {
+				c.Peaks = append(c.Peaks, number(math.Min(f.Peaks[ch][idx]/f.Scale, 1)))
+			}
+		}
+	}
+
+	r.Meter = nil
+	if m := f.Meter; m != nil {
+		r.Meter = &jsonMeter{
+			Momentary:   number(m.Loudness.Momentary),
+			ShortTerm:   number(m.Loudness.ShortTerm),
+			Integrated:  number(m.Loudness.Integrated),
+			MaxTruePeak: number(meter.DB(m.MaxTruePeak)),
+		}
+
+		for _, lv := range m.Levels {
+			r.Meter.Levels = append(r.Meter.Levels, jsonLevels{
+				RMS:      number(meter.DB(lv.RMS)),
+				Peak:     number(meter.DB(lv.Peak)),
+				TruePeak: number(meter.DB(lv.TruePeak)),
+			})
+		}
+	}
+
+	r.Stereo = nil
+	if st := f.Stereo; st != nil {
+		r.Stereo = &jsonStereo{
+			Correlation: number(st.Correlation),
+			Balance:     number(st.Balance),
+			MidSide:     number(st.MidSideRatio()),
+		}
+
+		for _, w := range f.Widths {
+			r.Stereo.Widths = append(r.Stereo.Widths, number(w))
+		}
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	s.w.Write(data)
+	return s.w.WriteByte('\n')
+}
+
+func (s *Stream) writeCSV(f *Frame) error {
+	if !s.started {
+		s.started = true
+		s.w.WriteString("time,channel,bar,low,high,value,peak\n")
+	}
+
+	var time = strconv.FormatFloat(seconds(f), 'f', 6, 64)
+
+	for ch := range f.Bars {
+		for idx := 0; idx < f.Count; idx++ {
+			var b = s.buf[:0]
+
+			b = append(b, time...)
+			b = append(b, ',')
+			b = strconv.AppendInt(b, int64(ch), 10)
+			b = append(b, ',')
+			b = strconv.AppendInt(b, int64(idx), 10)
+			b = append(b, ',')
+			b = strconv.AppendFloat(b, f.Range(idx).Low, 'f', 2, 64)
+			b = append(b, ',')
+			b = strconv.AppendFloat(b, f.Range(idx).High, 'f', 2, 64)
+			b = append(b, ',')
+			b = strconv.AppendFloat(b, f.Level(ch, idx), 'g', 6, 64)
+			b = append(b, ',')
+
+			// the peak column is empty without peaks.
+			if ch < len(f.Peaks) {
+				b = strconv.AppendFloat(b, math.Min(f.Peaks[ch][idx]/f.Scale, 1), 'g', 6, 64)
+			}
+
+			b = append(b, '\n')
+
+			s.buf = b
+			if _, err := s.w.Write(b); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Stream) writeBinary(f *Frame) error {
+	var flags uint8
+	if len(f.Peaks) > 0 {
+		flags |= BinaryPeaks
+	}
+
+	if f.Meter != nil {
+		flags |= BinaryMeter
+	}
+
+	if f.Stereo != nil {
+		flags |= BinaryStereo
+	}
+
+	var b = append(s.buf[:0], 0, 0, 0, 0)
+	var le = binary.LittleEndian
+
+	var float = func(v float64) {
+		b = append(b, 0, 0, 0, 0)
+		le.PutUint32(b[len(b)-4:], math.Float32bits(float32(v)))
+	}
+
+	b = append(b, make([]byte, 8+2+2+1)...)
+	le.PutUint64(b[4:], uint64(f.Time.UnixNano()))
+	le.PutUint16(b[12:], uint16(len(f.Bars)))
+	le.PutUint16(b[14:], uint16(f.Count))
+	b[16] = flags
+
+	for idx := 0; idx < f.Count; idx++ {
+		var rng = f.Range(idx)
+		float(rng.Low)
+		float(rng.High)
+	}
+
+	for ch := range f.Bars {
+		for idx := 0; idx < f.Count; idx++ {
+			float(f.Level(ch, idx))
+		}
+	}
+
+	if flags&BinaryPeaks != 0 {
+		for ch := range f.Bars {
+			for idx := 0; idx < f.Count; idx++ {
+				float(math.Min(f.Peaks[ch][idx]/f.Scale, 1))
+			}
+		}
+	}
+
+	if m := f.Meter; m != nil {
+		for _, lv := range m.Levels {
+			float(meter.DB(lv.RMS))
+			float(meter.DB(lv.Peak))
+			float(meter.DB(lv.TruePeak))
+		}
+
+		float(m.Loudness.Momentary)
+		float(m.Loudness.ShortTerm)
+		float(m.Loudness.Integrated)
+		float(meter.DB(m.MaxTruePeak))
+	}
+
+	if st := f.Stereo; st != nil {
+		float(st.Correlation)
+		float(st.Balance)
+		float(st.MidSideRatio())
+
+		for idx := 0; idx < f.Count; idx++ {
+			var w float64
+			if idx < len(f.Widths) {
+				w = f.Widths[idx]
+			}
+
+			float(w)
+		}
+	}
+
+	le.PutUint32(b, uint32(len(b)-4))
+	s.buf = b
+
+	_, err := s.w.Write(b)
+	return err
+}
@@ -0,0 +1,246 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noriah/catnip/dsp/meter"
+)
+
+func testStreamFrame() *Frame {
+	var f = testFrame(1500 * time.Millisecond)
+
+	f.Ranges = []Range{{0, 100}, {100, 200}, {200, 400}, {400, 800}}
+	f.Peaks = [][]float64{
+		{1, 1, 2, 4},
+		{0, 2, 1, 1},
+	}
+
+	return f
+}
+
+func TestStreamJSON(t *testing.T) {
+	var buf bytes.Buffer
+	var s = NewStream(&buf, StreamJSON, 4, 0)
+
+	var f = testStreamFrame()
+	f.Meter = &meter.Meter{
+		Levels:      []meter.Levels{{RMS: 0.5, Peak: 1, TruePeak: 1}, {}},
+		Loudness:    meter.Loudness{Momentary: -20, ShortTerm: math.Inf(-1), Integrated: math.Inf(-1)},
+		MaxTruePeak: 1,
+	}
+	f.Stereo = &meter.Stereo{Correlation: 1, Balance: 0.5, Mid: 1, Side: 0.1}
+	f.Widths = []float64{0, 0.5, 1, 0}
+
+	if err := s.Write(f); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Time     float64
+		Ranges   [][2]float64
+		Channels []struct {
+			Channel int
+			Bars    []float64
+			Peaks   []float64
+		}
+		Meter struct {
+			Levels []struct {
+				RMS  *float64
+				Peak *float64
+			}
+			Momentary *float64
+			ShortTerm *float64
+		}
+		Stereo struct {
+			Correlation float64
+			Balance     float64
+			MidSide     float64 `json:"mid_side"`
+			Widths      []float64
+		}
+	}
+
+	if !strings.HasSuffix(buf.String(), "}\n") {
+		t.Fatalf("not a line: %q", buf.String())
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Time != 1.5 {
+		t.Errorf("time %v, want 1.5", got.Time)
+	}
+
+	if len(got.Ranges) != 4 || got.Ranges[3] != [2]float64{400, 800} {
+		t.Errorf("ranges %v", got.Ranges)
+	}
+
+	if len(got.Channels) != 2 || got.Channels[1].Channel != 1 {
+		t.Fatalf("channels %+v", got.Channels)
+	}
+
+	var wantBars = []float64{0, 0.5, 0, 0.125}
+	var wantPeaks = []float64{0, 1, 0.5, 0.5}
+	for idx := range wantBars {
+		if got.Channels[1].Bars[idx] != wantBars[idx] {
+			t.Errorf("bar %d: %v, want %v", idx, got.Channels[1].Bars[idx], wantBars[idx])
+		}
+
+		if got.Channels[1].Peaks[idx] != wantPeaks[idx] {
+			t.Errorf("peak %d: %v, want %v", idx, got.Channels[1].Peaks[idx], wantPeaks[idx])
+		}
+	}
+
+	if lv := got.Meter.Levels; len(lv) != 2 || *lv[0].Peak != 0 || lv[1].RMS != nil {
+		t.Errorf("levels %+v", lv)
+	}
+
+	if got.Meter.ShortTerm != nil || *got.Meter.Momentary != -20 {
+		t.Errorf("silence must be null, got %+v", got.Meter)
+	}
+
+	if st := got.Stereo; st.Correlation != 1 || st.Balance != 0.5 || st.MidSide != 10 || len(st.Widths) != 4 {
+		t.Errorf("stereo %+v", got.Stereo)
+	}
+}
+
+func TestStreamCSV(t *testing.T) {
+	var buf bytes.Buffer
+	var s = NewStream(&buf, StreamCSV, 4, 0)
+
+	var f = testStreamFrame()
+	f.Peaks = nil
+
+	for i := 0; i < 2; i++ {
+		if err := s.Write(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	// a header and a row per bar of each channel, twice.
+	if len(lines) != 17 {
+		t.Fatalf("got %d lines, want 17", len(lines))
+	}
+
+	if lines[0] != "time,channel,bar,low,high,value,peak" {
+		t.Errorf("header %q", lines[0])
+	}
+
+	if want := "1.500000,0,2,200.00,400.00,1,"; lines[3] != want {
+		t.Errorf("row %q, want %q", lines[3], want)
+	}
+}
+
+func TestStreamBinary(t *testing.T) {
+	var buf bytes.Buffer
+	var s = NewStream(&buf, StreamBinary, 4, 0)
+
+	var f = testStreamFrame()
+	if err := s.Write(f); err != nil {
+		t.Fatal(err)
+	}
+
+	var le = binary.LittleEndian
+	var b = buf.Bytes()
+
+	// ranges, bars and peaks of 2 channels after the header.
+	if size := 4 + 8 + 2 + 2 + 1 + 4*(4*2+4*2+4*2); len(b) != size {
+		t.Fatalf("got %d bytes, want %d", len(b), size)
+	}
+
+	if n := le.Uint32(b); int(n) != len(b)-4 {
+		t.Errorf("size %d, want %d", n, len(b)-4)
+	}
+
+	if ns := int64(le.Uint64(b[4:])); ns != int64(1500*time.Millisecond) {
+		t.Errorf("time %d", ns)
+	}
+
+	if ch, count, flags := le.Uint16(b[12:]), le.Uint16(b[14:]), b[16]; ch != 2 || count != 4 || flags != BinaryPeaks {
+		t.Errorf("header %d %d %d", ch, count, flags)
+	}
+
+	var float = func(idx int) float32 {
+		return math.Float32frombits(le.Uint32(b[17+4*idx:]))
+	}
+
+	if low, high := float(6), float(7); low != 400 || high != 800 {
+		t.Errorf("range %v %v", low, high)
+	}
+
+	// bar 1 of channel 0 then peak 3 of channel 0.
+	if v := float(8 + 1); v != 0.25 {
+		t.Errorf("bar %v, want 0.25", v)
+	}
+
+	if v := float(16 + 3); v != 1 {
+		t.Errorf("peak %v, want 1", v)
+	}
+}
+
+func TestStreamJSONTime(t *testing.T) {
+	var buf bytes.Buffer
+	var s = NewStream(&buf, StreamJSON, 4, 0)
+
+	var f = testStreamFrame()
+	f.Time = time.Date(2025, 10, 18, 21, 4, 5, 250e6, time.UTC)
+
+	if err := s.Write(f); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct{ Time float64 }
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	// a wall clock time must keep its milliseconds.
+	if want := float64(f.Time.UnixNano()) / 1e9; math.Abs(got.Time-want) > 1e-6 {
+		t.Errorf("time %f, want %f", got.Time, want)
+	}
+}
+
+func TestStreamBinaryStereo(t *testing.T) {
+	var buf bytes.Buffer
+	var s = NewStream(&buf, StreamBinary, 4, 0)
+
+	var f = testStreamFrame()
+	f.Peaks = nil
+	f.Stereo = &meter.Stereo{Correlation: -1, Balance: 0.25, Mid: 0.1, Side: 1}
+	f.Widths = []float64{0, 0.25, 0.5, 1}
+
+	if err := s.Write(f); err != nil {
+		t.Fatal(err)
+	}
+
+	var le = binary.LittleEndian
+	var b = buf.Bytes()
+
+	if flags := b[16]; flags != BinaryStereo {
+		t.Fatalf("flags %d, want %d", flags, BinaryStereo)
+	}
+
+	// correlation, balance, mid to side ratio and widths after the ranges
+	// and bars.
+	var float = func(idx int) float32 {
+		return math.Float32frombits(le.Uint32(b[17+4*(8+8+idx):]))
+	}
+
+	if len(b) != 17+4*(8+8+3+4) {
+		t.Fatalf("got %d bytes", len(b))
+	}
+
+	for idx, want := range []float32{-1, 0.25, -10, 0, 0.25, 0.5, 1} {
+		if got := float(idx); got != want {
+			t.Errorf("stereo value %d: %v, want %v", idx, got, want)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package output
+
+import (
+	"bufio"
+	"encoding/binary"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WebQueue is the number of frames queued for a client. Frames are dropped
+// for a client while its queue is full, so a slow client skips frames instead
+// of holding back the others.
+const WebQueue = 4
+
+// webWriteTimeout is how long a client may block a write before it is
+// dropped. The close frame gets webCloseTimeout.
+const (
+	webWriteTimeout = 10 * time.Second
+	webCloseTimeout = time.Second
+)
+
+// web message flags, set if the part follows.
+const (
+	WebPeaks = 1 << iota
+	WebWaveform
+)
+
+// Web serves a browser visualizer over http and sends it every frame over a
+// websocket at /ws.
+//
+// A message is binary, all little endian:
+//
+//	uint16  channels
+//	uint16  bars
+//	uint16  samples of the waveform of each channel, 0 without it
+//	uint16  flags
+//	float32 bars of each channel in [0, 1]
+//	float32 peaks of each channel in [0, 1], if WebPeaks
+//	float32 samples of each channel, if WebWaveform
+type Web struct {
+	Count    int
+	Waveform bool
+
+	listener net.Listener
+	server   *http.Server
+	limit    limiter
+
+	mu      sync.Mutex
+	clients map[*webClient]struct{}
+	err     error
+}
+
+// webClient is a websocket connection. Frames are written by its own
+// goroutine.
+type webClient struct {
+	conn   net.Conn
+	frames chan []byte
+	done   chan struct{}
+
+	writeMu sync.Mutex
+	once    sync.Once
+}
+
+// NewWeb returns a web output listening on addr, sending count bars per
+// channel at most rate times a second, every frame if rate is 0. The waveform
+// is sent as well if waveform is true.
+//
+// An addr without a host, like ":8080", listens on all interfaces, so anyone
+// on the network can listen in. Use "127.0.0.1:8080" to keep it local.
+func NewWeb(addr string, count int, rate float64, waveform bool) (*Web, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to listen")
+	}
+
+	var web = &Web{
+		Count:    count,
+		Waveform: waveform,
+		listener: ln,
+		limit:    newLimiter(rate),
+		clients:  make(map[*webClient]struct{}),
+	}
+
+	var mux = http.NewServeMux()
+	mux.HandleFunc("/", web.servePage)
+	mux.HandleFunc("/ws", web.serveSocket)
+
+	web.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := web.server.Serve(ln); err != http.ErrServerClosed {
+			web.mu.Lock()
+			web.err = errors.Wrap(err, "web server failed")
+			web.mu.Unlock()
+		}
+	}()
+
+	return web, nil
+}
+
+// Addr returns the address the server listens on.
+func (web *Web) Addr() net.Addr {
+	return web.listener.Addr()
+}
+
+// Bars returns the bar count of the web output.
+func (web *Web) Bars(channels int) int {
+	return web.Count
+}
+
+// Write sends a frame to every client that has room for it.
+func (web *Web) Write(f *Frame) error {
+	web.mu.Lock()
+	var err, count = web.err, len(web.clients)
+	web.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if count == 0 || !web.limit.allow(f.Time) {
+		return nil
+	}
+
+	// the message is shared by all clients and must not be reused.
+	var msg = encodeFrame(opBinary, web.message(f))
+
+	web.mu.Lock()
+	for c := range web.clients {
+		c.send(msg)
+	}
+	web.mu.Unlock()
+
+	return nil
+}
+
+// Close stops the server and says goodbye to the clients.
+func (web *Web) Close() error {
+	var err = web.server.Close()
+
+	web.mu.Lock()
+	defer web.mu.Unlock()
+
+	for c := range web.clients {
+		c.close(closeGoingAway)
+		delete(web.clients, c)
+	}
+
+	return err
+}
+
+// message encodes the payload of a frame.
+func (web *Web) message(f *Frame) []byte {
+	var (
+		channels = len(f.Bars)
+		samples  int
+		flags    uint16
+	)
+
+	if len(f.Peaks) > 0 {
+		flags |= WebPeaks
+	}
+
+	if web.Waveform && len(f.Samples) > 0 {
+		flags |= WebWaveform
+		samples = len(f.Samples[0])
+	}
+
+	var size = 8 + 4*channels*f.Count
+	if flags&WebPeaks != 0 {
+		size += 4 * channels * f.Count
+	}
+
+	size += 4 * channels * samples
+
+	var b = make([]byte, 8, size)
+	var le = binary.LittleEndian
+
+	var float = func(v float64) {
+		b = append(b, 0, 0, 0, 0)
+		le.PutUint32(b[len(b)-4:], math.Float32bits(float32(v)))
+	}
+
+	le.PutUint16(b[0:], uint16(channels))
+	le.PutUint16(b[2:], uint16(f.Count))
+	le.PutUint16(b[4:], uint16(samples))
+	le.PutUint16(b[6:], flags)
+
+	for ch := 0; ch < channels; ch++ {
+		for idx := 0; idx < f.Count; idx++ {
+			float(f.Level(ch, idx))
+		}
+	}
+
+	if flags&WebPeaks != 0 {
+		for ch := 0; ch < channels; ch++ {
+			for idx := 0; idx < f.Count; idx++ {
+				float(math.Min(f.Peaks[ch][idx]/f.Scale, 1))
+			}
+		}
+	}
+
+	if flags&WebWaveform != 0 {
+		for ch := 0; ch < channels; ch++ {
+			for _, s := range f.Samples[ch][:samples] {
+				float(s)
+			}
+		}
+	}
+
+	return b
+}
+
+func (web *Web) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(webPage))
+}
+
+func (web *Web) serveSocket(w http.ResponseWriter, r *http.Request) {
+	conn, br, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+
+	var c = &webClient{
+		conn:   conn,
+		frames: make(chan []byte, WebQueue),
+		done:   make(chan struct{}),
+	}
+
+	web.mu.Lock()
+	web.clients[c] = struct{}{}
+	web.mu.Unlock()
+
+	defer func() {
+		web.mu.Lock()
+		delete(web.clients, c)
+		web.mu.Unlock()
+	}()
+
+	go c.writeFrames()
+
+	c.readFrames(br)
+}
+
+// send queues a frame for the client. It returns false if the frame was
+// dropped.
+func (c *webClient) send(msg []byte) bool {
+	select {
+	case c.frames <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// write writes a whole frame to the connection within timeout.
+func (c *webClient) write(msg []byte, timeout time.Duration) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(timeout))
+	_, err := c.conn.Write(msg)
+
+	return err
+}
+
+// writeFrames writes queued frames until the client is closed.
+func (c *webClient) writeFrames() {
+	for {
+		select {
+		case msg := <-c.frames:
+			if err := c.write(msg, webWriteTimeout); err != nil {
+				c.close(0)
+				return
+			}
+
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// readFrames answers control frames until the client leaves. Anything else
+// a client sends is ignored.
+func (c *webClient) readFrames(br *bufio.Reader) {
+	for {
+		op, payload, err := readFrame(br)
+
+		switch {
+		case err == errTooBig:
+			c.close(closeTooBig)
+			return
+
+		case err != nil:
+			c.close(closeProtocol)
+			return
+
+		case op == opClose:
+			c.close(closeNormal)
+			return
+
+		case op == opPing:
+			if err := c.write(encodeFrame(opPong, payload), webWriteTimeout); err != nil {
+				c.close(0)
+				return
+			}
+		}
+	}
+}
+
+// close sends a close frame with code, unless it is 0, and closes the
+// connection.
+func (c *webClient) close(code uint16) {
+	c.once.Do(func() {
+		close(c.done)
+
+		if code != 0 {
+			c.write(closeFrame(code), webCloseTimeout)
+		}
+
+		c.conn.Close()
+	})
+}
@@ -0,0 +1,269 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// the example of RFC 6455 section 1.3.
+	if got, want := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// maskFrame returns a masked frame as sent by a client.
+func maskFrame(op byte, payload []byte) []byte {
+	var frame = encodeFrame(op, payload)
+	var head = frame[:len(frame)-len(payload)]
+	var mask = []byte{1, 2, 3, 4}
+
+	var b = append([]byte{}, head...)
+	b[1] |= 0x80
+	b = append(b, mask...)
+
+	for idx, v := range payload {
+		b = append(b, v^mask[idx%4])
+	}
+
+	return b
+}
+
+func TestSameOrigin(t *testing.T) {
+	var tests = []struct {
+		host, origin string
+		want         bool
+	}{
+		{"localhost:8080", "", true},
+		{"localhost:8080", "http://localhost:8080", true},
+		{"tv.lan:8080", "http://TV.lan:8080", true},
+		{"localhost:8080", "https://evil.example", false},
+		{"localhost:8080", "http://localhost:9090", false},
+		{"localhost:8080", "null", false},
+	}
+
+	for _, tt := range tests {
+		var r = httptest.NewRequest(http.MethodGet, "/ws", nil)
+		r.Host = tt.host
+
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+
+		if got := sameOrigin(r); got != tt.want {
+			t.Errorf("host %q origin %q: got %v, want %v", tt.host, tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestFrames(t *testing.T) {
+	for _, n := range []int{0, 125, 126, 4000} {
+		var payload = bytes.Repeat([]byte{'x'}, n)
+
+		op, got, err := readFrame(bufio.NewReader(bytes.NewReader(maskFrame(opPing, payload))))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+
+		if op != opPing || !bytes.Equal(got, payload) {
+			t.Errorf("%d bytes: got op %d and %d bytes", n, op, len(got))
+		}
+	}
+
+	var big = maskFrame(opBinary, make([]byte, maxClientPayload+1))
+	if _, _, err := readFrame(bufio.NewReader(bytes.NewReader(big))); err != errTooBig {
+		t.Errorf("got %v, want %v", err, errTooBig)
+	}
+
+	var unmasked = encodeFrame(opPing, nil)
+	if _, _, err := readFrame(bufio.NewReader(bytes.NewReader(unmasked))); err == nil {
+		t.Error("unmasked client frame was accepted")
+	}
+
+	if head := encodeFrame(opBinary, make([]byte, 70000))[:10]; head[1] != 127 ||
+		binary.BigEndian.Uint64(head[2:]) != 70000 {
+		t.Errorf("bad 64 bit length header %v", head)
+	}
+}
+
+// dial opens a websocket to web.
+func dial(t *testing.T, web *Web) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", web.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Write([]byte("GET /ws HTTP/1.1\r\n" +
+		"Host: catnip\r\n" +
+		"Origin: http://catnip\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"))
+
+	var br = bufio.NewReader(conn)
+
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("got status %d", resp.StatusCode)
+	}
+
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("got accept %q", got)
+	}
+
+	return conn, br
+}
+
+// readServerFrame reads an unmasked frame.
+func readServerFrame(t *testing.T, conn net.Conn, br *bufio.Reader) (byte, []byte) {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var head [2]byte
+	if _, err := br.Read(head[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	head[1], _ = br.ReadByte()
+
+	var n = int(head[1] & 0x7f)
+	if n == 126 {
+		var ext [2]byte
+		br.Read(ext[:1])
+		ext[1], _ = br.ReadByte()
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+
+	var payload = make([]byte, n)
+	for read := 0; read < n; {
+		m, err := br.Read(payload[read:])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		read += m
+	}
+
+	return head[0] & 0x0f, payload
+}
+
+// waitClients waits until web has n clients.
+func waitClients(t *testing.T, web *Web, n int) {
+	for start := time.Now(); time.Since(start) < 5*time.Second; {
+		web.mu.Lock()
+		var count = len(web.clients)
+		web.mu.Unlock()
+
+		if count == n {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("never got %d clients", n)
+}
+
+func TestWeb(t *testing.T) {
+	web, err := NewWeb("127.0.0.1:0", 4, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer web.Close()
+
+	resp, err := http.Get("http://" + web.Addr().String() + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("page content type %q", ct)
+	}
+
+	conn, br := dial(t, web)
+	defer conn.Close()
+
+	waitClients(t, web, 1)
+
+	var f = testFrame(0)
+	f.Samples = [][]float64{{0.5, -0.5}, {1, -1}}
+
+	if err := web.Write(f); err != nil {
+		t.Fatal(err)
+	}
+
+	op, payload := readServerFrame(t, conn, br)
+	if op != opBinary {
+		t.Fatalf("got op %d", op)
+	}
+
+	var le = binary.LittleEndian
+	if ch, count, samples, flags := le.Uint16(payload), le.Uint16(payload[2:]),
+		le.Uint16(payload[4:]), le.Uint16(payload[6:]); ch != 2 || count != 4 || samples != 2 || flags != WebWaveform {
+		t.Fatalf("header %d %d %d %d", ch, count, samples, flags)
+	}
+
+	if size := 8 + 4*(2*4+2*2); len(payload) != size {
+		t.Fatalf("got %d bytes, want %d", len(payload), size)
+	}
+
+	var float = func(idx int) float32 {
+		return math.Float32frombits(le.Uint32(payload[8+4*idx:]))
+	}
+
+	// bar 1 of channel 1, then the last sample.
+	if v := float(5); v != 0.5 {
+		t.Errorf("bar %v, want 0.5", v)
+	}
+
+	if v := float(11); v != -1 {
+		t.Errorf("sample %v, want -1", v)
+	}
+
+	// a ping is answered and a close is echoed.
+	conn.Write(maskFrame(opPing, []byte("hi")))
+	if op, payload := readServerFrame(t, conn, br); op != opPong || string(payload) != "hi" {
+		t.Errorf("got op %d %q, want a pong", op, payload)
+	}
+
+	conn.Write(maskFrame(opClose, nil))
+	if op, _ := readServerFrame(t, conn, br); op != opClose {
+		t.Errorf("got op %d, want a close", op)
+	}
+
+	waitClients(t, web, 0)
+}
+
+func TestWebDrop(t *testing.T) {
+	var c = &webClient{frames: make(chan []byte, WebQueue)}
+
+	for idx := 0; idx < WebQueue; idx++ {
+		if !c.send([]byte{byte(idx)}) {
+			t.Fatalf("frame %d dropped with room in the queue", idx)
+		}
+	}
+
+	if c.send([]byte{WebQueue}) {
+		t.Error("frame queued for a full client")
+	}
+
+	// the queued frames are the oldest.
+	if msg := <-c.frames; msg[0] != 0 {
+		t.Errorf("got frame %d first", msg[0])
+	}
+}
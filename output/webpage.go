@@ -0,0 +1,180 @@
+package output
+
+// webPage is the browser visualizer served by Web. It draws the latest
+// message on every animation frame and reconnects if the socket closes.
+const webPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>catnip</title>
+<style>
+html, body { margin: 0; height: 100%; background: #000; overflow: hidden; }
+canvas { display: block; width: 100%; height: 100%; }
+#status { position: absolute; left: 8px; top: 8px; color: #666; font: 12px monospace; }
+</style>
+</head>
+<body>
+<canvas id="screen"></canvas>
+<div id="status">connecting</div>
+<script>
+"use strict";
+
+var WEB_PEAKS = 1, WEB_WAVEFORM = 2;
+
+var canvas = document.getElementById("screen");
+var label = document.getElementById("status");
+var ctx = canvas.getContext("2d");
+var frame = null;
+
+// parse decodes a message, see the Web docs for the layout.
+function parse(buf) {
+  var view = new DataView(buf);
+  var channels = view.getUint16(0, true);
+  var count = view.getUint16(2, true);
+  var samples = view.getUint16(4, true);
+  var flags = view.getUint16(6, true);
+  var off = 8;
+
+  function floats(n) {
+    var out = new Float32Array(n);
+    for (var i = 0; i < n; i++) {
+      out[i] = view.getFloat32(off, true);
+      off += 4;
+    }
+    return out;
+  }
+
+  function perChannel(n) {
+    var out = [];
+    for (var ch = 0; ch < channels; ch++) {
+      out.push(floats(n));
+    }
+    return out;
+  }
+
+  var f = { channels: channels, count: count, bars: perChannel(count) };
+  if (flags & WEB_PEAKS) {
+    f.peaks = perChannel(count);
+  }
+  if (flags & WEB_WAVEFORM) {
+    f.samples = perChannel(samples);
+  }
+  return f;
+}
+
+function resize() {
+  var ratio = window.devicePixelRatio || 1;
+  canvas.width = Math.floor(canvas.clientWidth * ratio);
+  canvas.height = Math.floor(canvas.clientHeight * ratio);
+}
+
+// gradient runs green to yellow to red from the base to the tip of a bar.
+function gradient(base, tip) {
+  var g = ctx.createLinearGradient(0, base, 0, tip);
+  g.addColorStop(0, "#00ff00");
+  g.addColorStop(0.5, "#ffff00");
+  g.addColorStop(1, "#ff0000");
+  return g;
+}
+
+// drawBars draws the bars of a channel growing from base by dir.
+function drawBars(bars, peaks, base, height, dir) {
+  var width = canvas.width / bars.length;
+  var gap = Math.max(1, Math.floor(width / 4));
+
+  ctx.fillStyle = gradient(base, base + dir * height);
+  for (var i = 0; i < bars.length; i++) {
+    var h = bars[i] * height;
+    ctx.fillRect(i * width, dir > 0 ? base : base - h, width - gap, h);
+  }
+
+  if (!peaks) {
+    return;
+  }
+
+  ctx.fillStyle = "#ffffff";
+  for (var i = 0; i < peaks.length; i++) {
+    var y = base + dir * peaks[i] * height;
+    ctx.fillRect(i * width, dir > 0 ? y - 2 : y, width - gap, 2);
+  }
+}
+
+function drawWave(samples, mid, height) {
+  ctx.strokeStyle = "rgba(255, 255, 255, 0.6)";
+  ctx.lineWidth = Math.max(1, window.devicePixelRatio || 1);
+  ctx.beginPath();
+  for (var i = 0; i < samples.length; i++) {
+    var x = i * canvas.width / (samples.length - 1);
+    var y = mid - samples[i] * height;
+    if (i === 0) {
+      ctx.moveTo(x, y);
+    } else {
+      ctx.lineTo(x, y);
+    }
+  }
+  ctx.stroke();
+}
+
+function draw() {
+  window.requestAnimationFrame(draw);
+
+  if (canvas.width !== Math.floor(canvas.clientWidth * (window.devicePixelRatio || 1))) {
+    resize();
+  }
+
+  ctx.fillStyle = "#000000";
+  ctx.fillRect(0, 0, canvas.width, canvas.height);
+
+  if (!frame) {
+    return;
+  }
+
+  var mid = canvas.height / 2;
+  var peaks = frame.peaks || [];
+
+  if (frame.channels === 2) {
+    // the left channel grows up and the right one down from the middle.
+    drawBars(frame.bars[0], peaks[0], mid, mid, -1);
+    drawBars(frame.bars[1], peaks[1], mid, mid, 1);
+  } else {
+    for (var ch = 0; ch < frame.channels; ch++) {
+      drawBars(frame.bars[ch], peaks[ch], canvas.height, canvas.height, -1);
+    }
+  }
+
+  if (frame.samples) {
+    var lane = canvas.height / frame.channels;
+    for (var ch = 0; ch < frame.channels; ch++) {
+      drawWave(frame.samples[ch], lane * (ch + 0.5), lane / 2);
+    }
+  }
+}
+
+function connect() {
+  var scheme = location.protocol === "https:" ? "wss://" : "ws://";
+  var ws = new WebSocket(scheme + location.host + "/ws");
+  ws.binaryType = "arraybuffer";
+
+  ws.onopen = function () {
+    label.textContent = "";
+  };
+
+  ws.onmessage = function (ev) {
+    frame = parse(ev.data);
+  };
+
+  ws.onclose = function () {
+    frame = null;
+    label.textContent = "disconnected, retrying";
+    window.setTimeout(connect, 1000);
+  };
+}
+
+resize();
+connect();
+window.requestAnimationFrame(draw);
+</script>
+</body>
+</html>
+`
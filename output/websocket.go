@@ -0,0 +1,222 @@
+package output
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// websocketGUID is appended to the key of a handshake, RFC 6455 section 1.3.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// websocket opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// websocket close codes
+const (
+	closeNormal    = 1000
+	closeGoingAway = 1001
+	closeProtocol  = 1002
+	closeTooBig    = 1009
+)
+
+// maxClientPayload is the largest frame a client may send. We only expect
+// control frames, which are limited to 125 bytes.
+const maxClientPayload = 4096
+
+var errTooBig = errors.New("websocket frame too big")
+
+// acceptKey returns the Sec-WebSocket-Accept value of a key.
+func acceptKey(key string) string {
+	var sum = sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerHas returns true if the comma separated header name has token.
+func headerHas(h http.Header, name, token string) bool {
+	for _, value := range h[http.CanonicalHeaderKey(name)] {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sameOrigin returns true if the request has no Origin, as sent by programs
+// other than browsers, or one with the host the request was sent to. Any page
+// a browser loads may open a websocket to us, so others are refused.
+func sameOrigin(r *http.Request) bool {
+	var origin = r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// upgrade answers a websocket handshake and takes over its connection. The
+// request is answered with an error if it is not a valid handshake or comes
+// from a page of another origin.
+func upgrade(w http.ResponseWriter, r *http.Request) (net.Conn, *bufio.Reader, error) {
+	var key = r.Header.Get("Sec-WebSocket-Key")
+
+	switch {
+	case r.Method != http.MethodGet:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, nil, errors.New("websocket handshake is not a GET")
+
+	case !headerHas(r.Header, "Connection", "upgrade"),
+		!headerHas(r.Header, "Upgrade", "websocket"):
+		http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+		return nil, nil, errors.New("not a websocket handshake")
+
+	case !sameOrigin(r):
+		http.Error(w, "cross origin websocket refused", http.StatusForbidden)
+		return nil, nil, errors.New("websocket origin does not match host")
+
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusBadRequest)
+		return nil, nil, errors.New("unsupported websocket version")
+	}
+
+	if raw, err := base64.StdEncoding.DecodeString(key); err != nil || len(raw) != 16 {
+		http.Error(w, "invalid websocket key", http.StatusBadRequest)
+		return nil, nil, errors.New("invalid websocket key")
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return nil, nil, errors.New("connection cannot be hijacked")
+	}
+
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to hijack connection")
+	}
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\n")
+	rw.WriteString("Connection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n")
+
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, nil, errors.Wrap(err, "failed to answer websocket handshake")
+	}
+
+	return conn, rw.Reader, nil
+}
+
+// encodeFrame returns an unmasked final frame of op with payload, as sent by
+// a server.
+func encodeFrame(op byte, payload []byte) []byte {
+	var n = len(payload)
+	var b = make([]byte, 0, n+10)
+
+	b = append(b, 0x80|op)
+
+	switch {
+	case n < 126:
+		b = append(b, byte(n))
+
+	case n <= 0xffff:
+		b = append(b, 126, 0, 0)
+		binary.BigEndian.PutUint16(b[2:], uint16(n))
+
+	default:
+		b = append(b, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(b[2:], uint64(n))
+	}
+
+	return append(b, payload...)
+}
+
+// closeFrame returns a close frame with code.
+func closeFrame(code uint16) []byte {
+	var payload [2]byte
+	binary.BigEndian.PutUint16(payload[:], code)
+
+	return encodeFrame(opClose, payload[:])
+}
+
+// readFrame reads a frame sent by a client. Client frames must be masked.
+func readFrame(r *bufio.Reader) (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return 0, nil, err
+	}
+
+	if head[0]&0x70 != 0 {
+		return 0, nil, errors.New("websocket extension bits set")
+	}
+
+	if head[1]&0x80 == 0 {
+		return 0, nil, errors.New("websocket client frame is not masked")
+	}
+
+	var op = head[0] & 0x0f
+	var n = uint64(head[1] & 0x7f)
+
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if n > maxClientPayload {
+		return op, nil, errTooBig
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+
+	var payload = make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	for idx := range payload {
+		payload[idx] ^= mask[idx%4]
+	}
+
+	return op, payload, nil
+}
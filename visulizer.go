@@ -1,78 +1,406 @@
 package main
 
 import (
-	"math"
+	"context"
+	"fmt"
+	"time"
 
 	"github.com/noriah/catnip/dsp"
+	"github.com/noriah/catnip/dsp/filter"
+	"github.com/noriah/catnip/dsp/meter"
+	"github.com/noriah/catnip/dsp/scale"
 	"github.com/noriah/catnip/dsp/window"
 	"github.com/noriah/catnip/fft"
 	"github.com/noriah/catnip/graphic"
 	"github.com/noriah/catnip/input"
-	"github.com/noriah/catnip/util"
+	"github.com/noriah/catnip/output"
 )
 
 type visualizer struct {
-	cfg *Config
+	cfg     *Config
+	maxSize int // largest fft size
 
-	slowWindow util.MovingWindow
-	fastWindow util.MovingWindow
-
-	fftBuf    []complex128
+	// inputBufs are written by the input session. They are added to the
+	// history, and the latest samples are copied into the frame so the
+	// pipeline is free to work on them in place.
 	inputBufs [][]input.Sample
-	barBufs   [][]float64
+	history   *dsp.History
+
+	frame          *dsp.Frame
+	transform      *dsp.Transform
+	pipeline       *dsp.Pipeline // spectrum bars
+	chromaPipeline *dsp.Pipeline // pitch class bars
+	wavePipeline   *dsp.Pipeline // filters only, to keep them current
+	peaks          bool          // the pipeline tracks peaks
 
-	plans    []*fft.Plan
+	scaler   scale.Scaler
 	spectrum dsp.Spectrum
+	chroma   dsp.Chroma
+
+	meter      *meter.Meter
+	meters     []graphic.Meter
+	meterText  []string
+	meterRange float64
+
+	stereo      meter.Stereo
+	stereoImage *graphic.Stereo
 
 	bars    int
 	display graphic.Display
+
+	// out replaces the display when set. Errors writing to it are kept in
+	// err and stop the session.
+	out      output.Output
+	outFrame output.Frame
+	cancel   context.CancelFunc
+	err      error
+
+	keys chan rune
+}
+
+// buildPipelines builds the spectrum and chroma pipelines from the stages
+// named in order. Both pipelines share the frame, filters, window, transform,
+// scaler and peak tracking. Pitch classes are folded into a single set of bars and
+// skip the stages that only make sense for spectrum bins. The wave pipeline
+// only runs the filters, so they keep up while waveforms are drawn.
+func (vis *visualizer) buildPipelines(order []string) error {
+	var (
+		channels  = len(vis.frame.Input)
+		size      = vis.cfg.SampleSize
+		frameRate = vis.cfg.SampleRate / float64(size)
+
+		win     = dsp.Window{Func: window.Lanczos}
+		scaling = dsp.Scale{Scaler: vis.scaler}
+		peaks   = dsp.NewPeakHold(channels, vis.maxSize, frameRate)
+
+		smoother       = dsp.NewSmoother(channels, vis.maxSize)
+		chromaSmoother = dsp.NewSmoother(channels, dsp.PitchClasses)
+	)
+
+	smoother.SetSmoothing(vis.cfg.SmoothFactor, size, vis.cfg.SampleRate)
+	chromaSmoother.SetSmoothing(vis.cfg.SmoothFactor, size, vis.cfg.SampleRate)
+
+	chain, err := filter.ParseChain(vis.cfg.Filters, vis.cfg.SampleRate)
+	if err != nil {
+		return err
+	}
+
+	var filters = dsp.NewFilter(chain, channels, vis.maxSize)
+
+	vis.transform = dsp.NewTransform(vis.frame, fft.Kind(vis.cfg.FFT))
+
+	vis.pipeline, err = dsp.NewPipeline(order, map[string]dsp.Stage{
+		dsp.StageFilter:    filters,
+		dsp.StageWindow:    win,
+		dsp.StageTransform: vis.transform,
+		dsp.StageBin:       dsp.StageFunc(vis.spectrum.Bin),
+		dsp.StageNoise:     &vis.spectrum.Noise,
+		dsp.StageWeight:    dsp.StageFunc(vis.spectrum.Weight),
+		dsp.StageSmooth:    smoother,
+		dsp.StageScale:     scaling,
+		dsp.StagePeak:      peaks,
+	})
+	if err != nil {
+		return err
+	}
+
+	vis.chromaPipeline, err = dsp.NewPipeline(order, map[string]dsp.Stage{
+		dsp.StageFilter:    filters,
+		dsp.StageWindow:    win,
+		dsp.StageTransform: vis.transform,
+		dsp.StageBin: dsp.StageFunc(func(f *dsp.Frame) {
+			vis.chroma.Bin(f)
+			dsp.Fold(f)
+		}),
+		dsp.StageNoise:  nil,
+		dsp.StageWeight: nil,
+		dsp.StageSmooth: chromaSmoother,
+		dsp.StageScale:  scaling,
+		dsp.StagePeak:   peaks,
+	})
+	if err != nil {
+		return err
+	}
+
+	vis.wavePipeline, err = dsp.NewPipeline(order, map[string]dsp.Stage{
+		dsp.StageFilter:    filters,
+		dsp.StageWindow:    nil,
+		dsp.StageTransform: nil,
+		dsp.StageBin:       nil,
+		dsp.StageNoise:     nil,
+		dsp.StageWeight:    nil,
+		dsp.StageSmooth:    nil,
+		dsp.StageScale:     nil,
+		dsp.StagePeak:      nil,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range order {
+		if name == dsp.StagePeak {
+			vis.peaks = true
+		}
+	}
+
+	return nil
 }
 
 // Process runs one draw refresh with the visualizer on the termbox screen.
-func (vis *visualizer) Process() {
-	if n := vis.display.Bars(vis.cfg.ChannelCount); n != vis.bars {
+func (vis *visualizer) Process(fresh bool) {
+	vis.handleKeys()
+
+	vis.frame.Fresh = 0
+	if fresh {
+		vis.frame.Fresh = vis.history.Write(vis.inputBufs)
+	}
+
+	vis.history.Read(vis.frame.Input)
+
+	vis.measure()
+
+	if vis.out != nil {
+		vis.output()
+		return
+	}
+
+	var f = vis.frame
+	var pipeline = vis.pipeline
+
+	// keys only change the draw type in handleKeys, so it holds for the
+	// whole frame.
+	var drawType = vis.display.DrawType()
+
+	switch drawType {
+	case graphic.DrawChroma, graphic.DrawChromaStrip:
+		pipeline = vis.chromaPipeline
+
+	case graphic.DrawScope, graphic.DrawScopeOverlay,
+		graphic.DrawVectorscope, graphic.DrawLissajous:
+		// the waveform needs no analysis, but the filters still see every
+		// fresh sample so their state is current when we switch back.
+		vis.wavePipeline.Process(f)
+		pipeline = nil
+
+	default:
+		if n := vis.display.Bars(vis.cfg.ChannelCount); n != vis.bars {
+			vis.bars = vis.spectrum.Recalculate(n)
+		}
+	}
+
+	if pipeline != nil {
+		pipeline.Process(f)
+
+		vis.measureWidths()
+
+		if vis.peaks {
+			vis.display.SetPeaks(f.Peaks[:f.Channels])
+		}
+	}
+
+	// the raw samples, before any filtering or windowing.
+	vis.display.SetSamples(vis.inputBufs)
+
+	vis.display.Draw(f.Bars[:f.Channels], f.Channels, f.Count, f.Scale)
+}
+
+// output runs the spectrum pipeline and writes the frame to the output.
+func (vis *visualizer) output() {
+	var out = &vis.outFrame
+
+	if n := vis.out.Bars(vis.cfg.ChannelCount); n != vis.bars {
 		vis.bars = vis.spectrum.Recalculate(n)
+
+		out.Ranges = out.Ranges[:0]
+		for idx := 0; idx < vis.bars; idx++ {
+			low, high := vis.spectrum.BinFrequencies(idx)
+			out.Ranges = append(out.Ranges, output.Range{Low: low, High: high})
+		}
 	}
 
-	var peak float64
+	var f = vis.frame
 
-	for idx := range vis.barBufs {
-		window.Lanczos(vis.inputBufs[idx])
-		vis.plans[idx].Execute()
+	vis.pipeline.Process(f)
 
-		buf := vis.barBufs[idx]
+	vis.measureWidths()
 
-		for bIdx := range buf[:vis.bars] {
-			v := vis.spectrum.ProcessBin(idx, bIdx, vis.fftBuf)
+	out.Time = time.Now()
+	out.Bars = f.Bars[:f.Channels]
+	out.Count = f.Count
+	out.Scale = f.Scale
+	out.Meter = vis.meter
+	out.Samples = vis.inputBufs
 
-			if peak < v {
-				peak = v
-			}
+	if vis.peaks {
+		out.Peaks = f.Peaks[:f.Channels]
+	}
 
-			buf[bIdx] = v
+	if vis.stereoImage != nil {
+		out.Stereo = &vis.stereo
+		out.Widths = vis.stereoImage.Widths[:vis.bars]
+	}
+
+	if err := vis.out.Write(out); err != nil && vis.err == nil {
+		vis.err = err
+		vis.cancel()
+	}
+}
+
+// onKey is the display key function. The keys are handled by the next call
+// to Process so we do not race with it.
+func (vis *visualizer) onKey(ch rune) bool {
+	switch ch {
+	case ' ', 'n', 'N', ',', '.', 'b', 'B', 't', 'T':
+	case '+', '=', '-', '_':
+		// these adjust the base unless we have a gain to adjust.
+		if _, ok := vis.scaler.(*scale.Manual); !ok {
+			return false
 		}
+	default:
+		return false
 	}
 
-	var scale = 1.0
+	select {
+	case vis.keys <- ch:
+	default:
+	}
 
-	// do some scaling if we are above the PeakThreshold
-	if peak >= PeakThreshold {
-		vis.fastWindow.Update(peak)
-		vMean, vSD := vis.slowWindow.Update(peak)
+	return true
+}
 
-		// if our slow window finally has more values than our fast window
-		if length := vis.slowWindow.Len(); length >= vis.fastWindow.Cap() {
-			// no idea what this is doing
-			if math.Abs(vis.fastWindow.Mean()-vMean) > (ScalingResetDeviation * vSD) {
-				// drop some values and continue
-				vMean, vSD = vis.slowWindow.Drop(int(float64(length) * ScalingDumpPercent))
-			}
+func (vis *visualizer) handleKeys() {
+	for {
+		select {
+		case ch := <-vis.keys:
+			vis.handleKey(ch)
+		default:
+			return
 		}
+	}
+}
+
+func (vis *visualizer) handleKey(ch rune) {
+	var noise = &vis.spectrum.Noise
+
+	switch ch {
+	case 'n':
+		// learning with the floor off would not do anything.
+		if noise.Mode == dsp.NoiseOff {
+			noise.Mode = dsp.NoiseSubtract
+		}
+		noise.Learn()
+
+	case 'N':
+		noise.Forget()
+
+	case '+', '=':
+		vis.scaler.(*scale.Manual).Adjust(1)
+
+	case '-', '_':
+		vis.scaler.(*scale.Manual).Adjust(-1)
+
+	case ',':
+		vis.resize(len(vis.frame.Input[0]) / 2)
+
+	case '.':
+		vis.resize(len(vis.frame.Input[0]) * 2)
+
+	case ' ':
+		vis.display.SetDrawType(vis.display.DrawType() + 1)
+
+	case 'b', 'B':
+		vis.display.SetBraille(!vis.display.Braille())
+
+	case 't':
+		vis.display.CycleTheme(1)
+
+	case 'T':
+		vis.display.CycleTheme(-1)
+	}
+}
+
+// resize changes the fft size. The fft can not be smaller than an input
+// buffer, so no samples are skipped, or larger than maxSize.
+func (vis *visualizer) resize(size int) {
+	if size < vis.cfg.SampleSize || size > vis.maxSize {
+		return
+	}
 
-		if t := vMean + (1.5 * vSD); t > 1.0 {
-			scale = t
+	vis.frame.Resize(size)
+	vis.transform.Resize(vis.frame)
+
+	vis.spectrum.Resize(size)
+	vis.chroma.SampleSize = size
+	vis.chroma.Recalculate()
+
+	// rebuild the bars on the next draw.
+	vis.bars = 0
+}
+
+// measure updates the meters with the fresh input, before any filtering.
+func (vis *visualizer) measure() {
+	if vis.frame.Fresh == 0 {
+		return
+	}
+
+	if vis.stereoImage != nil {
+		vis.stereo = meter.MeasureStereo(vis.inputBufs[0], vis.inputBufs[1])
+		vis.stereoImage.Correlation = vis.stereo.Correlation
+		vis.stereoImage.Balance = vis.stereo.Balance
+		vis.stereoImage.MidSide = vis.stereo.MidSideRatio()
+	}
+
+	if vis.meter == nil {
+		return
+	}
+
+	vis.meter.Write(vis.inputBufs)
+
+	var labels = "LR"
+	if len(vis.meter.Levels) == 1 {
+		labels = "C"
+	}
+
+	for idx, lv := range vis.meter.Levels {
+		vis.meters[idx] = graphic.Meter{
+			Label: labels[idx : idx+1],
+			Level: vis.meterLevel(meter.DB(lv.RMS)),
+			Peak:  vis.meterLevel(meter.DB(lv.TruePeak)),
 		}
 	}
 
-	vis.display.Draw(vis.barBufs, vis.cfg.ChannelCount, vis.bars, scale)
+	var loud = vis.meter.Loudness
+	var pos = len(vis.meter.Levels)
+
+	vis.meters[pos] = graphic.Meter{Label: "M", Level: vis.meterLevel(loud.Momentary)}
+	vis.meters[pos+1] = graphic.Meter{Label: "S", Level: vis.meterLevel(loud.ShortTerm)}
+
+	vis.meterText[0] = fmt.Sprintf("I %6.1f", loud.Integrated)
+	vis.meterText[1] = fmt.Sprintf("TP %5.1f", meter.DB(vis.meter.MaxTruePeak))
+
+	vis.display.SetMeters(vis.meters, vis.meterText)
+}
+
+// measureWidths updates the stereo width of each bar from the fft output.
+func (vis *visualizer) measureWidths() {
+	if vis.stereoImage == nil {
+		return
+	}
+
+	var widths = vis.stereoImage.Widths[:vis.bars]
+
+	for bIdx := range widths {
+		floor, ceil := vis.spectrum.BinRange(bIdx)
+		widths[bIdx] = meter.BandWidth(
+			vis.frame.Spectrum[0][floor:ceil],
+			vis.frame.Spectrum[1][floor:ceil],
+		)
+	}
+
+	vis.stereoImage.Widths = widths
+}
+
+// meterLevel maps decibels onto the meter range.
+func (vis *visualizer) meterLevel(db float64) float64 {
+	return (db + vis.meterRange) / vis.meterRange
 }